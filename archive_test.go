@@ -0,0 +1,132 @@
+// Copyright 2010-2025 the original author or authors.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+
+// 	http://www.apache.org/licenses/LICENSE-2.0
+
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logger_test
+
+import (
+	"compress/gzip"
+	"io/ioutil"
+	"os"
+	"path"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stella-go/logger"
+)
+
+func TestOpenArchive(t *testing.T) {
+	dir := t.TempDir()
+	writer, err := logger.NewConfigRotateWriter(&logger.RotateConfig{
+		MaxFiles: 5,
+		FilePath: dir,
+		FileName: "stella-go.log",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer writer.Close()
+	writer.Write([]byte("abc\n"))
+	if err := writer.Rotate(); err != nil {
+		t.Fatal(err)
+	}
+	archives, err := writer.Archives()
+	if err != nil || len(archives) != 1 {
+		t.Fatalf("unexpected archives %v %v", archives, err)
+	}
+	archive := path.Join(dir, archives[0].Name)
+	compress(t, archive)
+
+	archives, _ = writer.Archives()
+	if len(archives) != 1 || !archives[0].Compressed {
+		t.Fatalf("unexpected archives %+v", archives)
+	}
+	writer.Write([]byte("def\n"))
+	if s := readArchive(t, path.Join(dir, archives[0].Name)); s != "abc\n" {
+		t.Fatalf("unexpected archive content %q", s)
+	}
+	if s := readArchive(t, path.Join(dir, "stella-go.log")); s != "def\n" {
+		t.Fatalf("unexpected active content %q", s)
+	}
+	if _, err := logger.OpenArchive(path.Join(dir, "missing.gz")); err == nil {
+		t.Fatal("expected an error")
+	}
+}
+
+func TestTail(t *testing.T) {
+	dir := t.TempDir()
+	long := make([]string, 5000)
+	for i := range long {
+		long[i] = "line " + strconv.Itoa(i)
+	}
+	cases := []struct {
+		content  string
+		n        int
+		expected []string
+	}{
+		{"", 3, []string{}},
+		{"a\nb\n", 3, []string{"a", "b"}},
+		{"a\nb\nc", 2, []string{"b", "c"}},
+		{"a\n\nb\n", 2, []string{"", "b"}},
+		{"a\nb\n", 0, nil},
+		{strings.Join(long, "\n") + "\n", 3, long[4997:]},
+		{strings.Join(long, "\n"), 1000, long[4000:]},
+	}
+	for i, c := range cases {
+		name := path.Join(dir, strconv.Itoa(i)+".log")
+		os.WriteFile(name, []byte(c.content), 0644)
+		lines, err := logger.Tail(name, c.n)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(lines, c.expected) {
+			t.Fatalf("case %d: unexpected lines %q", i, lines)
+		}
+	}
+	if _, err := logger.Tail(path.Join(dir, "missing.log"), 1); err == nil {
+		t.Fatal("expected an error")
+	}
+}
+
+func readArchive(t *testing.T, name string) string {
+	r, err := logger.OpenArchive(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	b, err := ioutil.ReadAll(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(b)
+}
+
+func compress(t *testing.T, name string) {
+	b, err := os.ReadFile(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f, err := os.Create(name + ".gz")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	gz := gzip.NewWriter(f)
+	gz.Write(b)
+	if err := gz.Close(); err != nil {
+		t.Fatal(err)
+	}
+	os.Remove(name)
+}
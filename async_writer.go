@@ -0,0 +1,268 @@
+// Copyright 2010-2025 the original author or authors.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+
+// 	http://www.apache.org/licenses/LICENSE-2.0
+
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logger
+
+import (
+	"errors"
+	"io"
+	"os"
+	"sync"
+	"time"
+)
+
+var ErrClosed = errors.New("logger: writer is closed")
+
+// DefaultFlushInterval is the flush interval of NewAsyncWriter.
+const DefaultFlushInterval = 5 * time.Second
+
+// OverflowPolicy tells an AsyncWriter what to do with a line when its queue is full.
+type OverflowPolicy int
+
+const (
+	Block      OverflowPolicy = iota // wait for room in the queue
+	DropNewest                       // drop the line, the write returns ErrQueueFull
+	DropOldest                       // evict the oldest queued line to make room
+)
+
+// AsyncWriter serializes writes from any number of loggers through a single goroutine,
+// so loggers sharing a destination only contend on the queue instead of a shared mutex.
+type AsyncWriter struct {
+	stats    stats
+	writer   io.Writer
+	queue    chan asyncItem
+	done     chan struct{}
+	once     sync.Once
+	lock     sync.RWMutex // held for reading while sending to queue, see Close
+	closed   bool
+	interval time.Duration
+}
+
+func NewAsyncWriter(writer io.Writer, size int) *AsyncWriter {
+	return NewAsyncWriterFlush(writer, size, DefaultFlushInterval)
+}
+
+// NewAsyncWriterFlush flushes the writer every interval when it has a Flush method, such as
+// a *bufio.Writer, so lines do not sit in its buffer during quiet periods. The flush runs
+// on the background goroutine, the writer needs no locking of its own.
+func NewAsyncWriterFlush(writer io.Writer, size int, interval time.Duration) *AsyncWriter {
+	if size <= 0 {
+		size = 1024
+	}
+	if interval <= 0 {
+		interval = DefaultFlushInterval
+	}
+	w := &AsyncWriter{
+		writer:   writer,
+		queue:    make(chan asyncItem, size),
+		done:     make(chan struct{}),
+		interval: interval,
+	}
+	go w.loop()
+	return w
+}
+
+// Write queues p, waiting for room when the queue is full. It returns ErrClosed after Close.
+func (w *AsyncWriter) Write(p []byte) (int, error) {
+	return w.enqueue(p, Block)
+}
+
+// WriteEntry queues p, applying the overflow policy of the logger of e when the queue is full.
+func (w *AsyncWriter) WriteEntry(e *Entry, p []byte) (int, error) {
+	return w.enqueue(p, e.overflow)
+}
+
+func (w *AsyncWriter) enqueue(p []byte, policy OverflowPolicy) (int, error) {
+	w.lock.RLock()
+	defer w.lock.RUnlock()
+	if w.closed {
+		return 0, ErrClosed
+	}
+	line := make([]byte, len(p))
+	copy(line, p)
+	item := asyncItem{line: line}
+	select {
+	case w.queue <- item:
+		return len(p), nil
+	default:
+	}
+	w.stats.full()
+	switch policy {
+	case DropNewest:
+		w.stats.dropNewest()
+		return 0, ErrQueueFull
+	case DropOldest:
+		// an evicted barrier is queued again after the line, which only delays it
+		pending := []asyncItem{item}
+		for len(pending) > 0 {
+			select {
+			case w.queue <- pending[0]:
+				pending = pending[1:]
+				continue
+			default:
+			}
+			select {
+			case old := <-w.queue:
+				if old.barrier != nil {
+					pending = append(pending, old)
+				} else {
+					w.stats.dropOldest()
+				}
+			default:
+			}
+		}
+		return len(p), nil
+	default:
+		w.queue <- item
+		return len(p), nil
+	}
+}
+
+// asyncItem is a queued line, or a barrier when barrier is set.
+type asyncItem struct {
+	line    []byte
+	barrier chan error
+}
+
+// Barrier blocks until the lines queued before it are written and the writer is flushed
+// when it has a Flush method, and returns the error of that write or flush, or ErrClosed
+// after Close.
+func (w *AsyncWriter) Barrier() error {
+	barrier := make(chan error, 1)
+	w.lock.RLock()
+	if w.closed {
+		w.lock.RUnlock()
+		return ErrClosed
+	}
+	w.queue <- asyncItem{barrier: barrier}
+	w.lock.RUnlock()
+	return <-barrier
+}
+
+// QueueLen returns the number of lines waiting to be written.
+func (w *AsyncWriter) QueueLen() int {
+	return len(w.queue)
+}
+
+func (w *AsyncWriter) QueueCap() int {
+	return cap(w.queue)
+}
+
+// Stats counts the lines written by the background goroutine, QueueFull counts
+// the writes that found the queue full, whatever their overflow policy.
+func (w *AsyncWriter) Stats() Stats {
+	stats := w.stats.snapshot()
+	stats.QueueLen = w.QueueLen()
+	stats.QueueCap = w.QueueCap()
+	return stats
+}
+
+// Close drains the queue, flushes the writer, stops and closes the writer when it is an
+// io.Closer other than stdout or stderr. Writes waiting for room in the queue are written
+// first, later ones return ErrClosed.
+func (w *AsyncWriter) Close() error {
+	var err error
+	w.once.Do(func() {
+		w.lock.Lock()
+		w.closed = true
+		close(w.queue)
+		w.lock.Unlock()
+		<-w.done
+		if c, ok := w.writer.(io.Closer); ok && w.writer != os.Stdout && w.writer != os.Stderr {
+			err = c.Close()
+		}
+	})
+	<-w.done
+	return err
+}
+
+func (w *AsyncWriter) loop() {
+	defer close(w.done)
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+	buf := make([]byte, 0, 4096)
+	dirty := false
+	for {
+		select {
+		case item, ok := <-w.queue:
+			if !ok {
+				if dirty {
+					w.flush()
+				}
+				return
+			}
+			buf = w.write(buf, item)
+			dirty = true
+		case <-ticker.C:
+			if dirty {
+				w.flush()
+				dirty = false
+			}
+		}
+	}
+}
+
+// write writes item with the lines queued after it, up to a barrier that is released once
+// they are written and flushed.
+func (w *AsyncWriter) write(buf []byte, item asyncItem) []byte {
+	buf = append(buf[:0], item.line...)
+	lines := 1
+	barrier := item.barrier
+	if barrier != nil {
+		lines = 0
+	}
+drain:
+	for barrier == nil && len(buf) < 64*FileSizeK {
+		select {
+		case item, ok := <-w.queue:
+			if !ok {
+				break drain
+			}
+			if item.barrier != nil {
+				barrier = item.barrier
+				break drain
+			}
+			buf = append(buf, item.line...)
+			lines++
+		default:
+			break drain
+		}
+	}
+	var err error
+	if lines > 0 {
+		var n int
+		n, err = w.writer.Write(buf)
+		if err != nil {
+			print("AsyncWriter", "ERROR", "Write error: %v", err)
+		}
+		w.stats.writtenLines(lines, n, err)
+	}
+	if barrier != nil {
+		if ferr := w.flush(); err == nil {
+			err = ferr
+		}
+		barrier <- err
+	}
+	return buf
+}
+
+func (w *AsyncWriter) flush() error {
+	if f, ok := w.writer.(interface{ Flush() error }); ok {
+		if err := f.Flush(); err != nil {
+			print("AsyncWriter", "ERROR", "Flush error: %v", err)
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,284 @@
+// Copyright 2010-2025 the original author or authors.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+
+// 	http://www.apache.org/licenses/LICENSE-2.0
+
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logger_test
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"io/ioutil"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stella-go/logger"
+)
+
+func TestAsyncWriter(t *testing.T) {
+	buf := &bytes.Buffer{}
+	writer := logger.NewAsyncWriter(buf, 16)
+	loggers := make([]*logger.Logger, 4)
+	for i := range loggers {
+		loggers[i] = logger.NewRootLogger(logger.InfoLevel, &NopFormatter{}, writer)
+	}
+	wg := sync.WaitGroup{}
+	for _, l := range loggers {
+		wg.Add(1)
+		go func(l *logger.Logger) {
+			defer wg.Done()
+			for i := 0; i < 100; i++ {
+				l.INFO("line\n")
+			}
+		}(l)
+	}
+	wg.Wait()
+	writer.Close()
+	if buf.String() != strings.Repeat("line\n", 400) {
+		t.Fatalf("unexpected output length %d", buf.Len())
+	}
+}
+
+type lockedWriter struct {
+	lock   sync.Mutex
+	writer io.Writer
+}
+
+func (w *lockedWriter) Write(p []byte) (int, error) {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	return w.writer.Write(p)
+}
+
+func benchmarkSharedWriter(b *testing.B, writer io.Writer) {
+	loggers := make([]*logger.Logger, 16)
+	for i := range loggers {
+		loggers[i] = logger.NewRootLogger(logger.InfoLevel, &NopFormatter{}, writer)
+	}
+	var next int32
+	var mu sync.Mutex
+	b.ReportAllocs()
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		mu.Lock()
+		l := loggers[int(next)%len(loggers)]
+		next++
+		mu.Unlock()
+		for pb.Next() {
+			l.INFO("12345678901234567890123456789012\n")
+		}
+	})
+}
+
+func benchmarkFile(b *testing.B) *os.File {
+	f, err := ioutil.TempFile(b.TempDir(), "bench")
+	if err != nil {
+		b.Fatal(err)
+	}
+	return f
+}
+
+func BenchmarkSharedLockedWriter(b *testing.B) {
+	f := benchmarkFile(b)
+	defer f.Close()
+	benchmarkSharedWriter(b, &lockedWriter{writer: f})
+}
+
+func BenchmarkSharedAsyncWriter(b *testing.B) {
+	f := benchmarkFile(b)
+	defer f.Close()
+	writer := logger.NewAsyncWriter(f, 4096)
+	defer writer.Close()
+	benchmarkSharedWriter(b, writer)
+}
+
+func TestAsyncWriterStats(t *testing.T) {
+	writer := &BlockingWriter{release: make(chan struct{})}
+	asyncWriter := logger.NewAsyncWriter(writer, 2)
+	if asyncWriter.QueueCap() != 2 {
+		t.Fatalf("unexpected queue cap %d", asyncWriter.QueueCap())
+	}
+	// the first line holds the background goroutine in Write, so the queue cannot drain
+	asyncWriter.Write([]byte("12345"))
+	for atomic.LoadInt32(&writer.calls) == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 1; i < 10; i++ {
+			asyncWriter.Write([]byte("12345"))
+		}
+	}()
+	for asyncWriter.Stats().QueueFull == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	if stats := asyncWriter.Stats(); stats.QueueLen != 2 || stats.Lines != 0 {
+		t.Fatalf("unexpected stats %+v", stats)
+	}
+	close(writer.release)
+	<-done
+	asyncWriter.Close()
+	if stats := asyncWriter.Stats(); stats.Lines != 10 || stats.Bytes != 50 || stats.QueueFull == 0 || stats.QueueLen != 0 {
+		t.Fatalf("unexpected stats %+v", stats)
+	}
+}
+
+func TestAsyncWriterFlushInterval(t *testing.T) {
+	buf := &bytes.Buffer{}
+	dest := &lockedWriter{writer: buf}
+	writer := logger.NewAsyncWriterFlush(bufio.NewWriter(dest), 16, 10*time.Millisecond)
+	defer writer.Close()
+	writer.Write([]byte("line\n"))
+	deadline := time.Now().Add(time.Second)
+	for {
+		dest.lock.Lock()
+		out := buf.String()
+		dest.lock.Unlock()
+		if out == "line\n" {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("line not flushed, got %q", out)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+type GatedWriter struct {
+	release chan struct{}
+	lockedWriter
+}
+
+func (w *GatedWriter) Write(p []byte) (int, error) {
+	<-w.release
+	return w.lockedWriter.Write(p)
+}
+
+func TestOverflowPolicy(t *testing.T) {
+	cases := []struct {
+		policy   logger.OverflowPolicy
+		expected string
+		newest   int64
+		oldest   int64
+	}{
+		{logger.Block, "1234", 0, 0},
+		{logger.DropNewest, "12", 2, 0},
+		{logger.DropOldest, "14", 0, 2},
+	}
+	for _, c := range cases {
+		buf := &bytes.Buffer{}
+		writer := &GatedWriter{release: make(chan struct{}), lockedWriter: lockedWriter{writer: buf}}
+		asyncWriter := logger.NewAsyncWriter(writer, 1)
+		l := logger.NewRootLogger(logger.InfoLevel, &NopFormatter{}, asyncWriter).WithOverflowPolicy(c.policy)
+		l.INFO("1")
+		for asyncWriter.QueueLen() != 0 {
+			time.Sleep(time.Millisecond)
+		}
+		l.INFO("2")
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			l.INFO("3")
+			l.INFO("4")
+		}()
+		if c.policy == logger.Block {
+			for asyncWriter.Stats().QueueFull == 0 {
+				time.Sleep(time.Millisecond)
+			}
+		} else {
+			<-done
+		}
+		close(writer.release)
+		<-done
+		asyncWriter.Close()
+		stats := asyncWriter.Stats()
+		if buf.String() != c.expected || stats.DroppedNewest != c.newest || stats.DroppedOldest != c.oldest || stats.Dropped != c.newest+c.oldest {
+			t.Fatalf("policy %d: unexpected output %q, stats %+v", c.policy, buf.String(), stats)
+		}
+	}
+}
+
+func TestBarrier(t *testing.T) {
+	buf := &bytes.Buffer{}
+	dest := &lockedWriter{writer: buf}
+	asyncWriter := logger.NewAsyncWriterFlush(bufio.NewWriter(dest), 4, time.Hour)
+	defer asyncWriter.Close()
+	rootLogger := logger.NewRootLogger(logger.InfoLevel, &NopFormatter{}, logger.NewMultiSink(logger.Sink{Writer: asyncWriter}))
+	for i := 0; i < 100; i++ {
+		rootLogger.INFO("line\n")
+	}
+	if err := rootLogger.Barrier(); err != nil {
+		t.Fatal(err)
+	}
+	dest.lock.Lock()
+	out := buf.String()
+	dest.lock.Unlock()
+	if out != strings.Repeat("line\n", 100) {
+		t.Fatalf("unexpected output length %d", len(out))
+	}
+	if err := logger.NewRootLogger(logger.InfoLevel, &NopFormatter{}, &bytes.Buffer{}).Barrier(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+type ClosingBuffer struct {
+	lockedWriter
+	closed bool
+}
+
+func (b *ClosingBuffer) Close() error {
+	b.closed = true
+	return nil
+}
+
+func TestAsyncWriterClose(t *testing.T) {
+	buf := &bytes.Buffer{}
+	writer := &ClosingBuffer{lockedWriter: lockedWriter{writer: buf}}
+	asyncWriter := logger.NewAsyncWriter(writer, 16)
+	asyncWriter.Write([]byte("queued"))
+	if err := asyncWriter.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if !writer.closed || buf.String() != "queued" {
+		t.Fatalf("unexpected state closed=%v output=%q", writer.closed, buf.String())
+	}
+	if _, err := asyncWriter.Write([]byte("late")); err != logger.ErrClosed {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if err := asyncWriter.Barrier(); err != logger.ErrClosed {
+		t.Fatalf("unexpected barrier error %v", err)
+	}
+}
+
+func TestAsyncWriterCloseConcurrent(t *testing.T) {
+	asyncWriter := logger.NewAsyncWriter(ioutil.Discard, 1)
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 1000; j++ {
+				if _, err := asyncWriter.Write([]byte("12345")); err == logger.ErrClosed {
+					return
+				}
+			}
+		}()
+	}
+	asyncWriter.Close()
+	wg.Wait()
+}
@@ -0,0 +1,136 @@
+// Copyright 2010-2025 the original author or authors.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+
+// 	http://www.apache.org/licenses/LICENSE-2.0
+
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logger
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// BufferedLogger holds the entries of a unit of work, such as a request, until Flush writes
+// them or Discard drops them, so the detail of a failed request is logged without the
+// noise of the successful ones:
+//
+//	b := l.Buffered(100)
+//	defer func() {
+//		if err != nil {
+//			b.Flush()
+//		} else {
+//			b.Discard()
+//		}
+//	}()
+//
+// Entries keep the time they were logged at. Once max entries are held the oldest are
+// dropped, and Flush reports how many.
+type BufferedLogger struct {
+	logger  *Logger
+	max     int
+	entries []*Entry
+	dropped int
+	lock    sync.Mutex
+}
+
+// Buffered returns a BufferedLogger writing to l, holding at most max entries, 0 meaning 1000.
+func (l *Logger) Buffered(max int) *BufferedLogger {
+	if max <= 0 {
+		max = 1000
+	}
+	return &BufferedLogger{
+		logger: l,
+		max:    max,
+	}
+}
+
+func (b *BufferedLogger) DEBUG(format string, arr ...interface{}) {
+	b.logf(DebugLevel, format, arr)
+}
+
+func (b *BufferedLogger) INFO(format string, arr ...interface{}) {
+	b.logf(InfoLevel, format, arr)
+}
+
+func (b *BufferedLogger) WARN(format string, arr ...interface{}) {
+	b.logf(WarnLevel, format, arr)
+}
+
+func (b *BufferedLogger) ERROR(format string, arr ...interface{}) {
+	b.logf(ErrorLevel, format, arr)
+}
+
+func (b *BufferedLogger) Log(level Level, format string, arr ...interface{}) {
+	b.logf(level, format, arr)
+}
+
+// Len returns the number of entries held.
+func (b *BufferedLogger) Len() int {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	return len(b.entries)
+}
+
+// Flush writes the held entries in order and empties the buffer.
+func (b *BufferedLogger) Flush() {
+	b.lock.Lock()
+	entries, dropped := b.entries, b.dropped
+	b.entries, b.dropped = nil, 0
+	b.lock.Unlock()
+	if dropped > 0 {
+		b.logger.logEntry(&Entry{
+			Tag:     b.logger.tag,
+			Level:   WarnLevel,
+			Message: fmt.Sprintf("%d buffered lines dropped", dropped),
+			Fields:  b.logger.fields,
+		})
+	}
+	for _, e := range entries {
+		b.logger.logEntry(e)
+	}
+}
+
+// Discard drops the held entries.
+func (b *BufferedLogger) Discard() {
+	b.lock.Lock()
+	b.entries, b.dropped = nil, 0
+	b.lock.Unlock()
+}
+
+func (b *BufferedLogger) logf(level Level, format string, arr []interface{}) {
+	l := b.logger
+	if !l.internalLogger.enabled(level) {
+		return
+	}
+	arr, err := splitError(arr...)
+	msg := fmt.Sprintf(format, arr...)
+	if err != nil {
+		msg = fmt.Sprintf("%s %v", msg, err)
+	}
+	entry := &Entry{
+		Tag:     l.tag,
+		Level:   level,
+		Message: msg,
+		Fields:  l.fields,
+		Time:    time.Now(),
+		Caller:  callerPC(2),
+	}
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	if len(b.entries) == b.max {
+		copy(b.entries, b.entries[1:])
+		b.entries = b.entries[:len(b.entries)-1]
+		b.dropped++
+	}
+	b.entries = append(b.entries, entry)
+}
@@ -0,0 +1,57 @@
+// Copyright 2010-2025 the original author or authors.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+
+// 	http://www.apache.org/licenses/LICENSE-2.0
+
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logger_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stella-go/logger"
+)
+
+func TestBufferedLogger(t *testing.T) {
+	buf := &bytes.Buffer{}
+	rootLogger := logger.NewRootLogger(logger.InfoLevel, &LineFormatter{}, buf)
+	l := rootLogger.GetLogger("req")
+
+	ok := l.Buffered(10)
+	ok.INFO("start")
+	ok.Discard()
+	ok.Flush()
+	if buf.Len() != 0 {
+		t.Fatalf("unexpected output %q", buf.String())
+	}
+
+	failed := l.Buffered(2)
+	other := l.Buffered(10)
+	other.INFO("other")
+	failed.INFO("start %d", 1)
+	failed.DEBUG("hidden")
+	failed.INFO("query")
+	failed.ERROR("failed")
+	if failed.Len() != 2 {
+		t.Fatalf("unexpected length %d", failed.Len())
+	}
+	failed.Flush()
+	expected := "WARN  req - 1 buffered lines dropped\n" +
+		"INFO  req - query\n" +
+		"ERROR req - failed\n"
+	if buf.String() != expected {
+		t.Fatalf("unexpected output:\n%s", buf.String())
+	}
+	if failed.Len() != 0 || other.Len() != 1 {
+		t.Fatalf("unexpected lengths %d %d", failed.Len(), other.Len())
+	}
+}
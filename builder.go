@@ -0,0 +1,106 @@
+// Copyright 2010-2025 the original author or authors.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+
+// 	http://www.apache.org/licenses/LICENSE-2.0
+
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logger
+
+import (
+	"io"
+	"os"
+)
+
+// Builder collects the options of a root logger, New starts one with the defaults:
+// InfoLevel, DefaultFormatter and stdout.
+type Builder struct {
+	level     Level
+	formatter LogFormatter
+	writer    io.Writer
+	rotate    *RotateConfig
+	async     int
+	fields    map[string]interface{}
+	hooks     []Hook
+}
+
+func New() *Builder {
+	return &Builder{
+		level: InfoLevel,
+	}
+}
+
+func (b *Builder) Level(level Level) *Builder {
+	b.level = level
+	return b
+}
+
+func (b *Builder) Formatter(formatter LogFormatter) *Builder {
+	b.formatter = formatter
+	return b
+}
+
+// Writer sets the destination, replacing a previous Rotate.
+func (b *Builder) Writer(writer io.Writer) *Builder {
+	b.writer = writer
+	b.rotate = nil
+	return b
+}
+
+// Rotate writes to a RotateWriter opened with config by Build, replacing a previous Writer.
+func (b *Builder) Rotate(config *RotateConfig) *Builder {
+	b.rotate = config
+	b.writer = nil
+	return b
+}
+
+// Async puts an AsyncWriter with a queue of size lines in front of the writer, 0 writes synchronously.
+func (b *Builder) Async(size int) *Builder {
+	b.async = size
+	return b
+}
+
+// Fields adds fields to every entry of the built logger.
+func (b *Builder) Fields(fields map[string]interface{}) *Builder {
+	b.fields = mergeFields(b.fields, fields)
+	return b
+}
+
+// Hook adds a hook called with every entry of the built logger, hooks run in the order added.
+func (b *Builder) Hook(hook Hook) *Builder {
+	b.hooks = append(b.hooks, hook)
+	return b
+}
+
+// Build creates the logger, it fails when the rotate writer cannot be opened.
+func (b *Builder) Build() (*Logger, error) {
+	writer := b.writer
+	if b.rotate != nil {
+		rotateWriter, err := NewConfigRotateWriter(b.rotate)
+		if err != nil {
+			return nil, err
+		}
+		writer = rotateWriter
+	}
+	if writer == nil {
+		writer = os.Stdout
+	}
+	if b.async > 0 {
+		writer = NewAsyncWriter(writer, b.async)
+	}
+	formatter := b.formatter
+	if formatter == nil {
+		formatter = &DefaultFormatter{}
+	}
+	l := NewRootLogger(b.level, formatter, writer)
+	l.internalLogger.fields = b.fields
+	l.internalLogger.hooks = append([]Hook(nil), b.hooks...)
+	return l, nil
+}
@@ -0,0 +1,137 @@
+// Copyright 2010-2025 the original author or authors.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+
+// 	http://www.apache.org/licenses/LICENSE-2.0
+
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logger_test
+
+import (
+	"bytes"
+	"os"
+	"path"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/stella-go/logger"
+)
+
+func TestBuilder(t *testing.T) {
+	buf := &bytes.Buffer{}
+	rootLogger, err := logger.New().Level(logger.WarnLevel).Formatter(&LineFormatter{}).Writer(buf).Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	rootLogger.INFO("hidden")
+	rootLogger.WARN("shown")
+	if buf.String() != "WARN  ROOT - shown\n" {
+		t.Fatalf("unexpected output:\n%s", buf.String())
+	}
+
+	rootLogger, err = logger.New().Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rootLogger.Level() != logger.InfoLevel || rootLogger.Writer() != os.Stdout {
+		t.Fatalf("unexpected defaults %v %v", rootLogger.Level(), rootLogger.Writer())
+	}
+}
+
+func TestBuilderRotateAsync(t *testing.T) {
+	dir := t.TempDir()
+	rootLogger, err := logger.New().
+		Formatter(&logger.JSONFormatter{}).
+		Rotate(&logger.RotateConfig{FilePath: dir, FileName: "stella-go.log"}).
+		Async(16).
+		Fields(map[string]interface{}{"service": "api"}).
+		Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	rootLogger.INFO("started")
+	if err := rootLogger.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if isOpen(t, path.Join(dir, "stella-go.log")) {
+		t.Fatal("the log file is still open after Close")
+	}
+	b, _ := os.ReadFile(path.Join(dir, "stella-go.log"))
+	if !bytes.Contains(b, []byte(`"message":"started","fields":{"service":"api"}`)) {
+		t.Fatalf("unexpected content %s", b)
+	}
+
+	_, err = logger.New().Rotate(&logger.RotateConfig{FilePath: path.Join(dir, "missing"), FileName: "stella-go.log", NoCreateDir: true}).Build()
+	if err == nil {
+		t.Fatal("expected an error for a missing directory")
+	}
+}
+
+func TestBuilderFieldsDerived(t *testing.T) {
+	buf := &bytes.Buffer{}
+	rootLogger, err := logger.New().
+		Writer(buf).
+		Formatter(&logger.JSONFormatter{}).
+		Fields(map[string]interface{}{"svc": "api"}).
+		Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	rootLogger.GetLogger("db").INFO("connected")
+	if !bytes.Contains(buf.Bytes(), []byte(`"tag":"db"`)) || !bytes.Contains(buf.Bytes(), []byte(`"message":"connected","fields":{"svc":"api"}`)) {
+		t.Fatalf("unexpected content %s", buf.Bytes())
+	}
+}
+
+func TestBuilderHook(t *testing.T) {
+	buf := &bytes.Buffer{}
+	var hooked []string
+	rootLogger, err := logger.New().
+		Formatter(&LineFormatter{}).
+		Writer(buf).
+		Hook(func(e *logger.Entry) {
+			if e.Level >= logger.ErrorLevel {
+				hooked = append(hooked, e.Tag+" "+e.Message)
+			}
+		}).
+		Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	rootLogger.INFO("started")
+	rootLogger.ERROR("failed")
+	rootLogger.GetLogger("db").ERROR("lost connection")
+	if len(hooked) != 2 || hooked[0] != "ROOT failed" || hooked[1] != "db lost connection" {
+		t.Fatalf("unexpected hooked entries %q", hooked)
+	}
+	if buf.String() != "INFO  ROOT - started\nERROR ROOT - failed\nERROR db - lost connection\n" {
+		t.Fatalf("unexpected output:\n%s", buf.String())
+	}
+}
+
+// isOpen reports whether the process holds name open, always false where the open files
+// cannot be listed from /proc.
+func isOpen(t *testing.T, name string) bool {
+	if runtime.GOOS != "linux" {
+		return false
+	}
+	fds, err := os.ReadDir("/proc/self/fd")
+	if err != nil {
+		t.Log(err)
+		return false
+	}
+	for _, fd := range fds {
+		if target, err := os.Readlink(filepath.Join("/proc/self/fd", fd.Name())); err == nil && target == name {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,62 @@
+// Copyright 2010-2025 the original author or authors.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+
+// 	http://www.apache.org/licenses/LICENSE-2.0
+
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logger
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CSVFormatter formats entries as RFC 4180 records of time,level,tag,goroutine,message.
+type CSVFormatter struct {
+	Location *time.Location // time zone of the time column, nil means the SetTimeLayout zone
+}
+
+// Header returns the header record, write it once before the first entry, typically
+// only when the file is new.
+func (f *CSVFormatter) Header() []byte {
+	return []byte("time,level,tag,goroutine,message\r\n")
+}
+
+func (f *CSVFormatter) Format(e *Entry) []byte {
+	msg := make([]byte, 0, 64+len(e.Tag)+len(e.Message))
+	msg = appendCSVField(msg, entryTime(e, f.Location).Format(timeLayout("2006-01-02 15:04:05.000")))
+	msg = append(msg, ',')
+	msg = append(msg, strings.TrimSpace(e.Level.String())...)
+	msg = append(msg, ',')
+	msg = appendCSVField(msg, e.Tag)
+	msg = append(msg, ',')
+	msg = strconv.AppendUint(msg, gidFunc(), 10)
+	msg = append(msg, ',')
+	msg = appendCSVField(msg, e.Message)
+	msg = append(msg, "\r\n"...)
+	return msg
+}
+
+// appendCSVField quotes s when it holds a comma, a quote or a line break, doubling embedded quotes.
+func appendCSVField(b []byte, s string) []byte {
+	if !strings.ContainsAny(s, ",\"\r\n") {
+		return append(b, s...)
+	}
+	b = append(b, '"')
+	for i := 0; i < len(s); i++ {
+		if s[i] == '"' {
+			b = append(b, '"')
+		}
+		b = append(b, s[i])
+	}
+	return append(b, '"')
+}
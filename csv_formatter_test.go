@@ -0,0 +1,69 @@
+// Copyright 2010-2025 the original author or authors.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+
+// 	http://www.apache.org/licenses/LICENSE-2.0
+
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logger_test
+
+import (
+	"bytes"
+	"encoding/csv"
+	"testing"
+	"time"
+
+	"github.com/stella-go/logger"
+)
+
+func TestCSVFormatter(t *testing.T) {
+	formatter := &logger.CSVFormatter{}
+	buf := &bytes.Buffer{}
+	buf.Write(formatter.Header())
+	rootLogger := logger.NewRootLogger(logger.InfoLevel, formatter, buf)
+	rootLogger.INFO("a, b")
+	rootLogger.WARN("say \"hi\"\nbye")
+
+	records, err := csv.NewReader(buf).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 3 {
+		t.Fatalf("expected 3 records, got %d", len(records))
+	}
+	if records[0][4] != "message" {
+		t.Fatalf("unexpected header %v", records[0])
+	}
+	if records[1][1] != "INFO" || records[1][2] != "ROOT" || records[1][4] != "a, b" {
+		t.Fatalf("unexpected record %v", records[1])
+	}
+	if records[2][1] != "WARN" || records[2][4] != "say \"hi\"\nbye" {
+		t.Fatalf("unexpected record %v", records[2])
+	}
+}
+
+func TestCSVFormatterTimeLayout(t *testing.T) {
+	logger.SetTimeLayout(time.RFC1123, time.UTC)
+	defer logger.SetTimeLayout("", nil)
+	buf := &bytes.Buffer{}
+	rootLogger := logger.NewRootLogger(logger.InfoLevel, &logger.CSVFormatter{}, buf)
+	rootLogger.INFO("started")
+
+	records, err := csv.NewReader(buf).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 1 || len(records[0]) != 5 {
+		t.Fatalf("unexpected records %v", records)
+	}
+	if _, err := time.Parse(time.RFC1123, records[0][0]); err != nil || records[0][4] != "started" {
+		t.Fatalf("unexpected record %v: %v", records[0], err)
+	}
+}
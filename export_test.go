@@ -0,0 +1,68 @@
+// Copyright 2010-2025 the original author or authors.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+
+// 	http://www.apache.org/licenses/LICENSE-2.0
+
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logger
+
+import "time"
+
+var EnvRotateConfig = envRotateConfig
+
+var EnvWriter = envWriter
+
+func SetDefaultRootLogger(l *Logger) func() {
+	xInit()
+	old := defaultRootLogger
+	defaultRootLogger = &Logger{
+		tag:            l.tag,
+		internalLogger: l.internalLogger,
+		callerSkip:     1,
+	}
+	return func() {
+		defaultRootLogger = old
+	}
+}
+
+var ParseGid = parseGid
+
+var ShortFile = shortFile
+
+type OSFileSystem = osFileSystem
+
+type FileSystem = fileSystem
+
+type File = file
+
+func NewConfigRotateWriterFS(config *RotateConfig, fs FileSystem) (*RotateWriter, error) {
+	return newRotateWriter(config, fs)
+}
+
+var AppendGoroutine = appendGoroutine
+
+var InstallShutdown = installShutdown
+
+func SetInodeCheckInterval(d time.Duration) func() {
+	old := inodeCheckInterval
+	inodeCheckInterval = d
+	return func() {
+		inodeCheckInterval = old
+	}
+}
+
+func SetGidFunc(f func() uint64) func() {
+	old := gidFunc
+	gidFunc = f
+	return func() {
+		gidFunc = old
+	}
+}
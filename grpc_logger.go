@@ -0,0 +1,111 @@
+// Copyright 2010-2025 the original author or authors.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+
+// 	http://www.apache.org/licenses/LICENSE-2.0
+
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logger
+
+import (
+	"fmt"
+)
+
+// GRPCLogger adapts a Logger to grpclog.LoggerV2.
+//
+// Verbosity maps to levels as follows: V(0) is INFO, V(1) is DEBUG and V(2) or
+// higher is TRACE, so V(l) reports whether the mapped level is enabled.
+// The Fatal family logs at FATAL, flushes the writer and then calls ExitFunc(1).
+type GRPCLogger struct {
+	logger *Logger
+}
+
+func NewGRPCLogger(l *Logger) *GRPCLogger {
+	return &GRPCLogger{
+		logger: l,
+	}
+}
+
+func (g *GRPCLogger) Info(args ...interface{}) {
+	g.log(InfoLevel, fmt.Sprint(args...))
+}
+
+func (g *GRPCLogger) Infoln(args ...interface{}) {
+	g.log(InfoLevel, sprintln(args...))
+}
+
+func (g *GRPCLogger) Infof(format string, args ...interface{}) {
+	g.log(InfoLevel, fmt.Sprintf(format, args...))
+}
+
+func (g *GRPCLogger) Warning(args ...interface{}) {
+	g.log(WarnLevel, fmt.Sprint(args...))
+}
+
+func (g *GRPCLogger) Warningln(args ...interface{}) {
+	g.log(WarnLevel, sprintln(args...))
+}
+
+func (g *GRPCLogger) Warningf(format string, args ...interface{}) {
+	g.log(WarnLevel, fmt.Sprintf(format, args...))
+}
+
+func (g *GRPCLogger) Error(args ...interface{}) {
+	g.log(ErrorLevel, fmt.Sprint(args...))
+}
+
+func (g *GRPCLogger) Errorln(args ...interface{}) {
+	g.log(ErrorLevel, sprintln(args...))
+}
+
+func (g *GRPCLogger) Errorf(format string, args ...interface{}) {
+	g.log(ErrorLevel, fmt.Sprintf(format, args...))
+}
+
+func (g *GRPCLogger) Fatal(args ...interface{}) {
+	g.log(FatalLevel, fmt.Sprint(args...))
+	g.logger.exit(1)
+}
+
+func (g *GRPCLogger) Fatalln(args ...interface{}) {
+	g.log(FatalLevel, sprintln(args...))
+	g.logger.exit(1)
+}
+
+func (g *GRPCLogger) Fatalf(format string, args ...interface{}) {
+	g.log(FatalLevel, fmt.Sprintf(format, args...))
+	g.logger.exit(1)
+}
+
+func (g *GRPCLogger) V(l int) bool {
+	return grpcLevel(l) >= g.logger.Level()
+}
+
+func (g *GRPCLogger) log(level Level, msg string) {
+	entry := &Entry{
+		Tag:     g.logger.tag,
+		Level:   level,
+		Message: msg,
+		Caller:  callerPC(2),
+		Fields:  g.logger.fields,
+	}
+	g.logger.logEntry(entry)
+}
+
+func grpcLevel(l int) Level {
+	switch {
+	case l <= 0:
+		return InfoLevel
+	case l == 1:
+		return DebugLevel
+	default:
+		return TraceLevel
+	}
+}
@@ -0,0 +1,42 @@
+// Copyright 2010-2025 the original author or authors.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+
+// 	http://www.apache.org/licenses/LICENSE-2.0
+
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logger_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stella-go/logger"
+)
+
+func TestGRPCLogger(t *testing.T) {
+	buf := &bytes.Buffer{}
+	rootLogger := logger.NewRootLogger(logger.DebugLevel, &LineFormatter{}, buf)
+	grpcLogger := logger.NewGRPCLogger(rootLogger.GetLogger("gRPC"))
+
+	grpcLogger.Info("server", " started")
+	grpcLogger.Warningln("retry", 3)
+	grpcLogger.Errorf("code=%d", 14)
+
+	expected := "INFO  gRPC - server started\n" +
+		"WARN  gRPC - retry 3\n" +
+		"ERROR gRPC - code=14\n"
+	if buf.String() != expected {
+		t.Fatalf("unexpected output:\n%s", buf.String())
+	}
+	if !grpcLogger.V(0) || !grpcLogger.V(1) || grpcLogger.V(2) {
+		t.Fatal("unexpected verbosity mapping")
+	}
+}
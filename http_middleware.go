@@ -0,0 +1,104 @@
+// Copyright 2010-2025 the original author or authors.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+
+// 	http://www.apache.org/licenses/LICENSE-2.0
+
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logger
+
+import (
+	"bufio"
+	"fmt"
+	"net"
+	"net/http"
+	"time"
+)
+
+// AccessLogConfig configures Logger.AccessLog. Level and ErrorLevel are used as is, the
+// zero value of Level being TraceLevel.
+type AccessLogConfig struct {
+	Level      Level // level of the responses below 500
+	ErrorLevel Level // level of the 5xx responses
+	// Format returns the logged message, nil formats "GET /path 200 12B 3.2ms".
+	Format func(r *http.Request, status int, size int64, elapsed time.Duration) string
+}
+
+// HTTPMiddleware logs each request with its status, response size and duration, at INFO
+// level or at ERROR level for 5xx responses.
+func (l *Logger) HTTPMiddleware(next http.Handler) http.Handler {
+	return l.AccessLog(&AccessLogConfig{Level: InfoLevel, ErrorLevel: ErrorLevel}, next)
+}
+
+// AccessLog is HTTPMiddleware with the given levels and format.
+func (l *Logger) AccessLog(config *AccessLogConfig, next http.Handler) http.Handler {
+	format := config.Format
+	if format == nil {
+		format = accessLogFormat
+	}
+	level, errorLevel := config.Level, config.ErrorLevel
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		rw := &responseWriter{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(rw, r)
+		if rw.status >= 500 {
+			l.Log(errorLevel, "%s", format(r, rw.status, rw.size, time.Since(start)))
+		} else {
+			l.Log(level, "%s", format(r, rw.status, rw.size, time.Since(start)))
+		}
+	})
+}
+
+func accessLogFormat(r *http.Request, status int, size int64, elapsed time.Duration) string {
+	return fmt.Sprintf("%s %s %d %s %v", r.Method, r.URL.Path, status, FormatSize(size), elapsed)
+}
+
+// responseWriter records the status code and the number of bytes written.
+type responseWriter struct {
+	http.ResponseWriter
+	status      int
+	size        int64
+	wroteHeader bool
+}
+
+func (w *responseWriter) WriteHeader(status int) {
+	if !w.wroteHeader {
+		w.status = status
+		w.wroteHeader = true
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *responseWriter) Write(p []byte) (int, error) {
+	w.wroteHeader = true
+	n, err := w.ResponseWriter.Write(p)
+	w.size += int64(n)
+	return n, err
+}
+
+func (w *responseWriter) Flush() {
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Hijack lets handlers take over the connection, for example for WebSockets, when the
+// underlying writer supports it.
+func (w *responseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	if h, ok := w.ResponseWriter.(http.Hijacker); ok {
+		return h.Hijack()
+	}
+	return nil, nil, http.ErrNotSupported
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer.
+func (w *responseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
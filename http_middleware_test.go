@@ -0,0 +1,98 @@
+// Copyright 2010-2025 the original author or authors.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+
+// 	http://www.apache.org/licenses/LICENSE-2.0
+
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logger_test
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/stella-go/logger"
+)
+
+func TestHTTPMiddleware(t *testing.T) {
+	buf := &bytes.Buffer{}
+	l := logger.NewRootLogger(logger.InfoLevel, &LineFormatter{}, buf).GetLogger("http")
+	handler := l.HTTPMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/fail" {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		w.Write([]byte("hello"))
+	}))
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/ok?q=1", nil))
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("POST", "/fail", nil))
+	if !regexp.MustCompile(`^INFO  http - GET /ok 200 5B \S+\nERROR http - POST /fail 502 0B \S+\n$`).Match(buf.Bytes()) {
+		t.Fatalf("unexpected output %q", buf.String())
+	}
+}
+
+func TestAccessLog(t *testing.T) {
+	buf := &bytes.Buffer{}
+	l := logger.NewRootLogger(logger.DebugLevel, &LineFormatter{}, buf).GetLogger("http")
+	handler := l.AccessLog(&logger.AccessLogConfig{
+		Level:      logger.DebugLevel,
+		ErrorLevel: logger.WarnLevel,
+		Format: func(r *http.Request, status int, size int64, elapsed time.Duration) string {
+			return fmt.Sprintf("%s %d %d", r.URL, status, size)
+		},
+	}, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "boom", http.StatusInternalServerError)
+	}))
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest("GET", "/a?b=c", nil))
+	if recorder.Code != http.StatusInternalServerError {
+		t.Fatalf("unexpected status %d", recorder.Code)
+	}
+	if buf.String() != "WARN  http - /a?b=c 500 5\n" {
+		t.Fatalf("unexpected output %q", buf.String())
+	}
+}
+
+func TestHTTPMiddlewareHijack(t *testing.T) {
+	l := logger.NewRootLogger(logger.InfoLevel, &NopFormatter{}, &bytes.Buffer{})
+	handler := l.HTTPMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, rw, err := w.(http.Hijacker).Hijack()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		defer conn.Close()
+		rw.WriteString("HTTP/1.1 200 OK\r\nContent-Length: 8\r\nConnection: close\r\n\r\nhijacked")
+		rw.Flush()
+	}))
+	server := httptest.NewServer(handler)
+	defer server.Close()
+
+	resp, err := http.Get(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if b, _ := io.ReadAll(resp.Body); string(b) != "hijacked" {
+		t.Fatalf("unexpected body %q", b)
+	}
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest("GET", "/", nil))
+	if recorder.Code != http.StatusInternalServerError {
+		t.Fatalf("expected hijacking a recorder to fail, got %d", recorder.Code)
+	}
+}
@@ -0,0 +1,251 @@
+// Copyright 2010-2025 the original author or authors.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+
+// 	http://www.apache.org/licenses/LICENSE-2.0
+
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logger
+
+import (
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"sync"
+	"time"
+)
+
+var ErrQueueFull = errors.New("logger: queue is full")
+
+// HTTPConfig configures an HTTPWriter, zero values fall back to the defaults noted below.
+type HTTPConfig struct {
+	URL           string
+	Headers       map[string]string
+	BatchSize     int           // lines per request, default 100
+	FlushInterval time.Duration // max time a line waits in a batch, default 1s
+	QueueSize     int           // lines buffered before dropping, default 1024
+	Gzip          bool
+	MaxRetries    int           // retries on network errors and 5xx, default 3
+	RetryBackoff  time.Duration // first retry delay, doubled on each retry, default 500ms
+	Client        *http.Client  // default http.DefaultClient
+}
+
+// HTTPWriter batches written lines and POSTs them as newline-delimited bodies,
+// it is intended to be used with a JSON formatter.
+type HTTPWriter struct {
+	config  *HTTPConfig
+	queue   chan httpItem
+	done    chan struct{}
+	closing chan struct{} // closed by Close, cuts the retry backoff short
+	once    sync.Once
+	lock    sync.RWMutex // held for reading while sending to queue, see Close
+	closed  bool
+}
+
+// httpItem is a queued line, or a barrier when barrier is set.
+type httpItem struct {
+	line    []byte
+	barrier chan error
+}
+
+func NewHTTPWriter(config *HTTPConfig) (*HTTPWriter, error) {
+	if config.URL == "" {
+		return nil, errors.New("logger: http writer url is empty")
+	}
+	c := *config
+	if c.BatchSize <= 0 {
+		c.BatchSize = 100
+	}
+	if c.FlushInterval <= 0 {
+		c.FlushInterval = time.Second
+	}
+	if c.QueueSize <= 0 {
+		c.QueueSize = 1024
+	}
+	if c.MaxRetries < 0 {
+		c.MaxRetries = 0
+	} else if c.MaxRetries == 0 {
+		c.MaxRetries = 3
+	}
+	if c.RetryBackoff <= 0 {
+		c.RetryBackoff = 500 * time.Millisecond
+	}
+	if c.Client == nil {
+		c.Client = http.DefaultClient
+	}
+	w := &HTTPWriter{
+		config:  &c,
+		queue:   make(chan httpItem, c.QueueSize),
+		done:    make(chan struct{}),
+		closing: make(chan struct{}),
+	}
+	go w.loop()
+	return w, nil
+}
+
+// Write queues p, it returns ErrQueueFull when the queue is full and ErrClosed after Close.
+func (w *HTTPWriter) Write(p []byte) (int, error) {
+	w.lock.RLock()
+	defer w.lock.RUnlock()
+	if w.closed {
+		return 0, ErrClosed
+	}
+	line := make([]byte, len(p))
+	copy(line, p)
+	select {
+	case w.queue <- httpItem{line: line}:
+		return len(p), nil
+	default:
+		return 0, ErrQueueFull
+	}
+}
+
+// Barrier blocks until the lines queued before it are posted and returns the error of the
+// last request, or ErrClosed after Close.
+func (w *HTTPWriter) Barrier() error {
+	barrier := make(chan error, 1)
+	w.lock.RLock()
+	if w.closed {
+		w.lock.RUnlock()
+		return ErrClosed
+	}
+	w.queue <- httpItem{barrier: barrier}
+	w.lock.RUnlock()
+	return <-barrier
+}
+
+// Close sends the queued lines and stops the writer, later writes return ErrClosed.
+// Failed requests are still retried, but without waiting between attempts.
+func (w *HTTPWriter) Close() error {
+	w.once.Do(func() {
+		w.lock.Lock()
+		w.closed = true
+		close(w.closing)
+		close(w.queue)
+		w.lock.Unlock()
+	})
+	<-w.done
+	return nil
+}
+
+func (w *HTTPWriter) loop() {
+	defer close(w.done)
+	ticker := time.NewTicker(w.config.FlushInterval)
+	defer ticker.Stop()
+	batch := make([][]byte, 0, w.config.BatchSize)
+	for {
+		select {
+		case item, ok := <-w.queue:
+			if !ok {
+				w.send(batch)
+				return
+			}
+			if item.barrier != nil {
+				item.barrier <- w.send(batch)
+				batch = batch[:0]
+				continue
+			}
+			batch = append(batch, item.line)
+			if len(batch) >= w.config.BatchSize {
+				w.send(batch)
+				batch = batch[:0]
+			}
+		case <-ticker.C:
+			w.send(batch)
+			batch = batch[:0]
+		}
+	}
+}
+
+func (w *HTTPWriter) send(batch [][]byte) error {
+	if len(batch) == 0 {
+		return nil
+	}
+	body, err := w.encode(batch)
+	if err != nil {
+		print("HTTPWriter", "ERROR", "Encode body error: %v", err)
+		return err
+	}
+	backoff := w.config.RetryBackoff
+	for attempt := 0; ; attempt++ {
+		retry, err := w.post(body)
+		if err == nil {
+			return nil
+		}
+		if !retry || attempt >= w.config.MaxRetries {
+			print("HTTPWriter", "ERROR", "Post logs error: %v", err)
+			return err
+		}
+		timer := time.NewTimer(backoff)
+		select {
+		case <-timer.C:
+		case <-w.closing:
+			timer.Stop()
+		}
+		backoff *= 2
+	}
+}
+
+func (w *HTTPWriter) encode(batch [][]byte) ([]byte, error) {
+	buf := &bytes.Buffer{}
+	var dst io.Writer = buf
+	var gz *gzip.Writer
+	if w.config.Gzip {
+		gz = gzip.NewWriter(buf)
+		dst = gz
+	}
+	for _, line := range batch {
+		if _, err := dst.Write(line); err != nil {
+			return nil, err
+		}
+		if len(line) == 0 || line[len(line)-1] != '\n' {
+			if _, err := dst.Write([]byte{'\n'}); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if gz != nil {
+		if err := gz.Close(); err != nil {
+			return nil, err
+		}
+	}
+	return buf.Bytes(), nil
+}
+
+func (w *HTTPWriter) post(body []byte) (bool, error) {
+	req, err := http.NewRequest(http.MethodPost, w.config.URL, bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Content-Type", "application/x-ndjson")
+	if w.config.Gzip {
+		req.Header.Set("Content-Encoding", "gzip")
+	}
+	for k, v := range w.config.Headers {
+		req.Header.Set(k, v)
+	}
+	resp, err := w.config.Client.Do(req)
+	if err != nil {
+		return true, err
+	}
+	io.Copy(ioutil.Discard, resp.Body)
+	resp.Body.Close()
+	if resp.StatusCode >= 500 {
+		return true, fmt.Errorf("unexpected status %s", resp.Status)
+	}
+	if resp.StatusCode >= 300 {
+		return false, fmt.Errorf("unexpected status %s", resp.Status)
+	}
+	return false, nil
+}
@@ -0,0 +1,144 @@
+// Copyright 2010-2025 the original author or authors.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+
+// 	http://www.apache.org/licenses/LICENSE-2.0
+
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logger_test
+
+import (
+	"compress/gzip"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stella-go/logger"
+)
+
+func TestHTTPWriter(t *testing.T) {
+	var mu sync.Mutex
+	bodies := make([]string, 0)
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		calls++
+		if calls == 1 {
+			rw.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		if r.Header.Get("X-Api-Key") != "secret" || r.Header.Get("Content-Encoding") != "gzip" {
+			t.Errorf("unexpected headers: %v", r.Header)
+		}
+		gz, err := gzip.NewReader(r.Body)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		body, _ := ioutil.ReadAll(gz)
+		bodies = append(bodies, string(body))
+	}))
+	defer server.Close()
+
+	writer, err := logger.NewHTTPWriter(&logger.HTTPConfig{
+		URL:           server.URL,
+		Headers:       map[string]string{"X-Api-Key": "secret"},
+		BatchSize:     2,
+		FlushInterval: time.Hour,
+		Gzip:          true,
+		RetryBackoff:  time.Millisecond,
+		Client:        server.Client(),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	writer.Write([]byte(`{"msg":"a"}` + "\n"))
+	writer.Write([]byte(`{"msg":"b"}` + "\n"))
+	writer.Write([]byte(`{"msg":"c"}`))
+	writer.Close()
+
+	mu.Lock()
+	defer mu.Unlock()
+	if calls != 3 || len(bodies) != 2 {
+		t.Fatalf("unexpected calls %d, bodies %v", calls, bodies)
+	}
+	if bodies[0] != "{\"msg\":\"a\"}\n{\"msg\":\"b\"}\n" || bodies[1] != "{\"msg\":\"c\"}\n" {
+		t.Fatalf("unexpected bodies %q", bodies)
+	}
+}
+
+func TestHTTPWriterClose(t *testing.T) {
+	var mu sync.Mutex
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		calls++
+		rw.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	writer, err := logger.NewHTTPWriter(&logger.HTTPConfig{
+		URL:           server.URL,
+		FlushInterval: time.Hour,
+		MaxRetries:    2,
+		RetryBackoff:  time.Hour,
+		Client:        server.Client(),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	writer.Write([]byte("a\n"))
+	start := time.Now()
+	writer.Close()
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Fatalf("Close waited %v for the retry backoff", elapsed)
+	}
+	mu.Lock()
+	if calls != 3 {
+		t.Fatalf("unexpected calls %d", calls)
+	}
+	mu.Unlock()
+	if _, err := writer.Write([]byte("b\n")); err != logger.ErrClosed {
+		t.Fatalf("unexpected error %v", err)
+	}
+}
+
+func TestHTTPWriterBarrier(t *testing.T) {
+	var mu sync.Mutex
+	var bodies []string
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		mu.Lock()
+		defer mu.Unlock()
+		bodies = append(bodies, string(body))
+	}))
+	defer server.Close()
+
+	writer, err := logger.NewHTTPWriter(&logger.HTTPConfig{URL: server.URL, FlushInterval: time.Hour, Client: server.Client()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer writer.Close()
+	rootLogger := logger.NewRootLogger(logger.InfoLevel, &NopFormatter{}, writer)
+	rootLogger.INFO("a\n")
+	if err := rootLogger.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if len(bodies) != 1 || bodies[0] != "a\n" {
+		t.Fatalf("unexpected bodies %q", bodies)
+	}
+}
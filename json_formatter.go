@@ -0,0 +1,116 @@
+// Copyright 2010-2025 the original author or authors.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+
+// 	http://www.apache.org/licenses/LICENSE-2.0
+
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logger
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// JSONFormatter formats entries as one JSON object per line, with the fields under "fields"
+// keeping their JSON types: numbers and bools stay native, errors render as their message
+// and other values go through json.Marshal.
+type JSONFormatter struct {
+	Location *time.Location // time zone of the time field, nil means the SetTimeLayout zone
+	// ReportCaller adds the "caller" field, such as "main.go:42", resolving the caller of
+	// every entry, which costs about as much as %L.
+	ReportCaller bool
+}
+
+func (f *JSONFormatter) Format(e *Entry) []byte {
+	msg := make([]byte, 0, 128+len(e.Tag)+len(e.Message))
+	msg = append(msg, `{"time":"`...)
+	msg = entryTime(e, f.Location).AppendFormat(msg, timeLayout(time.RFC3339Nano))
+	msg = append(msg, `","level":"`...)
+	msg = append(msg, strings.TrimSpace(e.Level.String())...)
+	msg = append(msg, `","tag":`...)
+	msg = appendJSONValue(msg, e.Tag)
+	msg = append(msg, `,"goroutine":`...)
+	msg = strconv.AppendUint(msg, gidFunc(), 10)
+	if e.Seq > 0 {
+		msg = append(msg, `,"seq":`...)
+		msg = strconv.AppendUint(msg, e.Seq, 10)
+	}
+	msg = append(msg, `,"message":`...)
+	msg = appendJSONValue(msg, e.Message)
+	if f.ReportCaller {
+		if caller := appendFileLine(nil, e.Caller, true); len(caller) > 0 {
+			msg = append(msg, `,"caller":`...)
+			msg = appendJSONValue(msg, string(caller))
+		}
+	}
+	if len(e.Fields) > 0 {
+		keys := make([]string, 0, len(e.Fields))
+		for k := range e.Fields {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		msg = append(msg, `,"fields":{`...)
+		for i, k := range keys {
+			if i > 0 {
+				msg = append(msg, ',')
+			}
+			msg = appendJSONValue(msg, k)
+			msg = append(msg, ':')
+			msg = appendJSONValue(msg, e.Fields[k])
+		}
+		msg = append(msg, '}')
+	}
+	msg = append(msg, "}\n"...)
+	return msg
+}
+
+func appendJSONValue(b []byte, v interface{}) []byte {
+	switch v := v.(type) {
+	case nil:
+		return append(b, "null"...)
+	case bool:
+		return strconv.AppendBool(b, v)
+	case int:
+		return strconv.AppendInt(b, int64(v), 10)
+	case int64:
+		return strconv.AppendInt(b, v, 10)
+	case int32:
+		return strconv.AppendInt(b, int64(v), 10)
+	case uint:
+		return strconv.AppendUint(b, uint64(v), 10)
+	case uint64:
+		return strconv.AppendUint(b, v, 10)
+	case float64:
+		return appendJSONFloat(b, v, 64)
+	case float32:
+		return appendJSONFloat(b, float64(v), 32)
+	case error:
+		return appendJSONValue(b, v.Error())
+	}
+	p, err := json.Marshal(v)
+	if err != nil {
+		p, _ = json.Marshal(fmt.Sprint(v))
+	}
+	return append(b, p...)
+}
+
+// appendJSONFloat writes NaN and infinities as strings since JSON has no literal for them.
+func appendJSONFloat(b []byte, f float64, bitSize int) []byte {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return appendJSONValue(b, strconv.FormatFloat(f, 'g', -1, bitSize))
+	}
+	return strconv.AppendFloat(b, f, 'g', -1, bitSize)
+}
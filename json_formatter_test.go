@@ -0,0 +1,87 @@
+// Copyright 2010-2025 the original author or authors.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+
+// 	http://www.apache.org/licenses/LICENSE-2.0
+
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logger_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"math"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/stella-go/logger"
+)
+
+func TestJSONFormatter(t *testing.T) {
+	type point struct {
+		X int `json:"x"`
+		Y int `json:"y"`
+	}
+	buf := &bytes.Buffer{}
+	rootLogger := logger.NewRootLogger(logger.InfoLevel, &logger.JSONFormatter{}, buf)
+	rootLogger.WithFields(map[string]interface{}{
+		"count": 5,
+		"ratio": 0.25,
+		"ok":    true,
+		"none":  nil,
+		"point": point{1, 2},
+		"err":   errors.New("boom"),
+		"nan":   math.NaN(),
+	}).INFO("say \"hi\"")
+
+	var out map[string]interface{}
+	decoder := json.NewDecoder(buf)
+	decoder.UseNumber()
+	if err := decoder.Decode(&out); err != nil {
+		t.Fatal(err)
+	}
+	if out["level"] != "INFO" || out["tag"] != "ROOT" || out["message"] != "say \"hi\"" {
+		t.Fatalf("unexpected entry %v", out)
+	}
+	if _, ok := out["goroutine"].(json.Number); !ok {
+		t.Fatalf("unexpected goroutine %v", out["goroutine"])
+	}
+	fields := out["fields"].(map[string]interface{})
+	if fields["count"] != json.Number("5") || fields["ratio"] != json.Number("0.25") {
+		t.Fatalf("unexpected numbers %v %v", fields["count"], fields["ratio"])
+	}
+	if fields["ok"] != true || fields["none"] != nil || fields["err"] != "boom" || fields["nan"] != "NaN" {
+		t.Fatalf("unexpected fields %v", fields)
+	}
+	if p := fields["point"].(map[string]interface{}); p["x"] != json.Number("1") || p["y"] != json.Number("2") {
+		t.Fatalf("unexpected point %v", p)
+	}
+}
+
+func TestJSONFormatterReportCaller(t *testing.T) {
+	buf := &bytes.Buffer{}
+	rootLogger := logger.NewRootLogger(logger.InfoLevel, &logger.JSONFormatter{}, buf)
+	rootLogger.INFO("hidden caller")
+	if strings.Contains(buf.String(), `"caller"`) {
+		t.Fatalf("unexpected caller %s", buf.String())
+	}
+	buf.Reset()
+	rootLogger.SetFormatter(&logger.JSONFormatter{ReportCaller: true})
+	rootLogger.INFO("with caller")
+	var out map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &out); err != nil {
+		t.Fatal(err)
+	}
+	if caller, _ := out["caller"].(string); !regexp.MustCompile(`^json_formatter_test\.go:\d+$`).MatchString(caller) {
+		t.Fatalf("unexpected caller %v", out["caller"])
+	}
+}
@@ -16,16 +16,33 @@ package logger
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
 	"io"
 	"os"
 	"runtime"
+	"sort"
 	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
+	"unicode/utf8"
 )
 
+var ErrWriteTimeout = errors.New("logger: write timed out")
+
+// ErrWriteStalled and ErrWriteResumed are reported to the SetOnError function when a write
+// times out while the writer is still running, and when that write eventually returns.
+var (
+	ErrWriteStalled = errors.New("logger: writer stalled, lines are dropped until it returns")
+	ErrWriteResumed = errors.New("logger: stalled writer returned, lines are written again")
+)
+
+// ExitFunc is called by FATAL after logging. Tests may replace it to observe the exit code,
+// production code must leave it as os.Exit.
+var ExitFunc = os.Exit
+
 type Level int
 
 func (level Level) String() string {
@@ -49,6 +66,12 @@ func (level Level) String() string {
 	}
 }
 
+// Short returns the first letter of the level name: T, D, I, W, E, F and P,
+// which are unique across the seven levels. Unknown levels return L.
+func (level Level) Short() string {
+	return level.String()[:1]
+}
+
 func Parse(slevel string) Level {
 	slevel = strings.TrimSpace(strings.ToUpper(slevel))
 	switch slevel {
@@ -81,38 +104,218 @@ const (
 	PanicLevel
 )
 
+// AllLevels returns every level from the lowest to the highest, levels are ordered
+// so they can be compared with the usual operators.
+func AllLevels() []Level {
+	return []Level{TraceLevel, DebugLevel, InfoLevel, WarnLevel, ErrorLevel, FatalLevel, PanicLevel}
+}
+
+// Enabled reports whether a message at level l passes the threshold.
+func (l Level) Enabled(threshold Level) bool {
+	return l >= threshold
+}
+
 type Entry struct {
 	Tag     string
 	Level   Level
 	Message string
+	Fields  map[string]interface{}
+	Time    time.Time // set when the entry is written, if not set by the caller
+	Caller  uintptr   // program counter of the logging call, resolved only by formatters that print it
+	// Seq numbers the entries written by a root logger and its derived loggers from 1, so
+	// consumers can spot dropped or reordered lines. It restarts with the process.
+	Seq uint64
+
+	overflow OverflowPolicy // set by the logger, see WithOverflowPolicy
 }
 
 type LogFormatter interface {
 	Format(e *Entry) []byte
 }
 
-type DefaultFormatter struct{}
+// NewCompactFormatter returns a short single line formatter for consoles: time, level,
+// tag and message, without goroutine, caller or fields.
+func NewCompactFormatter() LogFormatter {
+	return &PatternFormatter{Pattern: "%d %p %c - %m"}
+}
+
+// NewVerboseFormatter returns the DefaultFormatter with the caller's file:line, for files
+// read when investigating. It includes the fields like the DefaultFormatter.
+func NewVerboseFormatter() LogFormatter {
+	return &DefaultFormatter{ShowCaller: true}
+}
 
-func (*DefaultFormatter) Format(e *Entry) []byte {
-	ts := time.Now().Format("06-01-02.15:04:05.000")
-	ts = ts + "000000000000000000000"
-	timestamp := ts[:21]
-	goroutine := gid()
-	msg := fmt.Sprintf("%s [%s] %s %s - %s\n", timestamp, goroutine, e.Level.String(), e.Tag, e.Message)
-	return []byte(msg)
+type DefaultFormatter struct {
+	Location *time.Location // time zone of the timestamps, nil means the SetTimeLayout zone
+	TagWidth int            // pads or truncates the tag to this width, 0 keeps it as is
+	// HideGoroutine leaves out the goroutine column, whose id is read from runtime.Stack
+	// and is the most expensive part of the line.
+	HideGoroutine  bool
+	GoroutineWidth int  // digits the goroutine id is right-justified to, 0 means 4
+	GoroutineLabel bool // prints the goroutine as "goroutine-N" like earlier versions
+	ShowCaller     bool // adds the file:line of the logging call after the tag
 }
 
-func gid() string {
-	b := make([]byte, 64)
-	b = b[:runtime.Stack(b, false)]
-	b = bytes.TrimPrefix(b, []byte("goroutine "))
-	b = b[:bytes.IndexByte(b, ' ')]
-	n, _ := strconv.ParseUint(string(b), 10, 64)
-	return fmt.Sprintf("%s-%-4d", "goroutine", n)
+func (f *DefaultFormatter) Format(e *Entry) []byte {
+	msg := make([]byte, 0, 64+len(e.Tag)+len(e.Message))
+	msg = appendDate(msg, entryTime(e, f.Location))
+	if f.HideGoroutine {
+		msg = append(msg, ' ')
+	} else {
+		msg = append(msg, " ["...)
+		msg = appendGoroutine(msg, gidFunc(), f.GoroutineWidth, f.GoroutineLabel)
+		msg = append(msg, "] "...)
+	}
+	msg = append(msg, e.Level.String()...)
+	msg = append(msg, ' ')
+	msg = appendWidth(msg, e.Tag, f.TagWidth)
+	if f.ShowCaller && e.Caller != 0 {
+		msg = append(msg, ' ')
+		msg = appendFileLine(msg, e.Caller, true)
+	}
+	msg = append(msg, " - "...)
+	msg = append(msg, e.Message...)
+	msg = appendFields(msg, e.Fields)
+	msg = append(msg, '\n')
+	return msg
+}
+
+// appendFields appends fields as " {k1=v1, k2=v2}" sorted by key, nothing when there are none.
+func appendFields(b []byte, fields map[string]interface{}) []byte {
+	if len(fields) == 0 {
+		return b
+	}
+	keys := make([]string, 0, len(fields))
+	for k := range fields {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	b = append(b, " {"...)
+	for i, k := range keys {
+		if i > 0 {
+			b = append(b, ", "...)
+		}
+		b = append(b, k...)
+		b = append(b, '=')
+		b = append(b, fmt.Sprint(fields[k])...)
+	}
+	return append(b, '}')
+}
+
+// entryTime returns the time of e in loc, nil means the zone of SetTimeLayout or local.
+func entryTime(e *Entry, loc *time.Location) time.Time {
+	t := e.Time
+	if t.IsZero() {
+		t = time.Now()
+	}
+	if loc == nil {
+		loc = timeSetting().location
+	}
+	if loc == nil {
+		return t
+	}
+	return t.In(loc)
+}
+
+// dateLayout is 21 characters wide for any date, the layout has no variable width element.
+const dateLayout = "06-01-02.15:04:05.000"
+
+func appendDate(b []byte, t time.Time) []byte {
+	return t.AppendFormat(b, timeLayout(dateLayout))
+}
+
+type timeSettings struct {
+	layout   string
+	location *time.Location
+}
+
+var timeSettingsValue atomic.Value
+
+// SetTimeLayout sets the timestamp layout and zone of every built-in formatter, such as
+// time.RFC3339Nano and time.UTC, so all components print comparable timestamps. An empty
+// layout keeps each formatter's own and a nil zone means local. The Location and
+// DefaultDateLayout fields of a formatter still win. Rotation keeps its 20060102 archive
+// suffix, in the zone of RotateConfig.Location.
+func SetTimeLayout(layout string, loc *time.Location) {
+	timeSettingsValue.Store(timeSettings{layout: layout, location: loc})
+}
+
+func timeSetting() timeSettings {
+	settings, _ := timeSettingsValue.Load().(timeSettings)
+	return settings
+}
+
+// timeLayout returns the layout of SetTimeLayout, or def when it is not set.
+func timeLayout(def string) string {
+	if layout := timeSetting().layout; layout != "" {
+		return layout
+	}
+	return def
+}
+
+// appendGoroutine appends the text form of the goroutine id, right-justified to width
+// digits, 0 meaning 4. With label it keeps the earlier "goroutine-N" form, left-justified.
+// Structured formatters write the bare number instead.
+func appendGoroutine(b []byte, id uint64, width int, label bool) []byte {
+	if width <= 0 {
+		width = 4
+	}
+	var digits [20]byte
+	n := strconv.AppendUint(digits[:0], id, 10)
+	if label {
+		b = append(b, "goroutine-"...)
+		b = append(b, n...)
+		for i := len(n); i < width; i++ {
+			b = append(b, ' ')
+		}
+		return b
+	}
+	for i := len(n); i < width; i++ {
+		b = append(b, ' ')
+	}
+	return append(b, n...)
+}
+
+// gidFunc returns the goroutine id printed by the formatters, tests replace it to get a
+// fixed id. The MDC keys on gid itself.
+var gidFunc = gid
+
+// gid returns the id of the calling goroutine.
+func gid() uint64 {
+	stack := make([]byte, 64)
+	return parseGid(stack[:runtime.Stack(stack, false)])
+}
+
+// parseGid reads the id from the "goroutine N [...]" header of a stack trace, it returns 0 when the header is malformed.
+func parseGid(stack []byte) uint64 {
+	stack = bytes.TrimPrefix(stack, []byte("goroutine "))
+	i := bytes.IndexByte(stack, ' ')
+	if i < 0 {
+		return 0
+	}
+	n, err := strconv.ParseUint(string(stack[:i]), 10, 64)
+	if err != nil {
+		return 0
+	}
+	return n
 }
 
 type PatternFormatter struct {
 	Pattern string
+	// FillEmpty renders placeholders that resolve to an empty value as EmptyToken,
+	// which defaults to "-", so columns stay aligned for parsers.
+	FillEmpty  bool
+	EmptyToken string
+	Location   *time.Location // time zone of %d, nil means the SetTimeLayout zone
+	TagWidth   int            // pads or truncates %c to this width, 0 keeps it as is
+	// GoroutineWidth and GoroutineLabel render %g like the DefaultFormatter fields.
+	GoroutineWidth int
+	GoroutineLabel bool
+	// DefaultDateLayout is the layout of %d without braces, empty means 06-01-02.15:04:05.000.
+	DefaultDateLayout string
+	// TrimPathPrefix is cut from the file names of %L, such as the module root of the build
+	// machine, so "/home/runner/work/app/pkg/svc/handler.go:42" prints as "pkg/svc/handler.go:42".
+	TrimPathPrefix string
 }
 
 func (p *PatternFormatter) Format(e *Entry) []byte {
@@ -123,36 +326,78 @@ func (p *PatternFormatter) Format(e *Entry) []byte {
 			switch pattern[i+1] {
 			case 'd':
 				start := i + 2
-				if pattern[start] == '{' {
-					end := strings.Index(pattern[start:], "}")
-					if end != -1 {
+				if start < len(pattern) && pattern[start] == '{' {
+					if end := strings.IndexByte(pattern[start:], '}'); end != -1 {
 						end += start
-						dateFormat := pattern[start+1 : end]
-						msg = append(msg, time.Now().Format(dateFormat)...)
+						msg = entryTime(e, p.Location).AppendFormat(msg, pattern[start+1:end])
 						i = end
-					} else {
-						ts := time.Now().Format("06-01-02.15:04:05.000")
-						ts = ts + "000000000000000000000"
-						msg = append(msg, ts[:21]...)
-						i++
+						break
 					}
+				}
+				if p.DefaultDateLayout != "" {
+					msg = entryTime(e, p.Location).AppendFormat(msg, p.DefaultDateLayout)
 				} else {
-					ts := time.Now().Format("06-01-02.15:04:05.000")
-					ts = ts + "000000000000000000000"
-					msg = append(msg, ts[:21]...)
-					i++
+					msg = appendDate(msg, entryTime(e, p.Location))
 				}
+				i++
+			case 'U':
+				msg = strconv.AppendInt(msg, entryTime(e, nil).Unix(), 10)
+				i++
+			case 'u':
+				msg = strconv.AppendInt(msg, entryTime(e, nil).UnixNano()/1e6, 10)
+				i++
 			case 'p':
 				msg = append(msg, e.Level.String()...)
 				i++
+			case 'P':
+				msg = append(msg, e.Level.Short()...)
+				i++
 			case 'c':
-				msg = append(msg, e.Tag...)
+				tag := e.Tag
 				i++
+				if i+1 < len(pattern) && pattern[i+1] == '{' {
+					if end := strings.IndexByte(pattern[i+1:], '}'); end != -1 {
+						end += i + 1
+						if n, err := strconv.Atoi(pattern[i+2 : end]); err == nil && n > 0 {
+							tag = lastSegments(tag, n)
+							i = end
+						}
+					}
+				}
+				start := len(msg)
+				msg = p.appendValue(msg, tag)
+				msg = fitWidth(msg, start, p.TagWidth)
 			case 'm':
-				msg = append(msg, e.Message...)
+				msg = p.appendValue(msg, e.Message)
 				i++
 			case 'g':
-				msg = append(msg, gid()...)
+				msg = appendGoroutine(msg, gidFunc(), p.GoroutineWidth, p.GoroutineLabel)
+				i++
+			case 'q':
+				msg = strconv.AppendUint(msg, e.Seq, 10)
+				i++
+			case 'X':
+				if i+2 < len(pattern) && pattern[i+2] == '{' {
+					if end := strings.IndexByte(pattern[i+2:], '}'); end != -1 {
+						end += i + 2
+						msg = p.appendValue(msg, mdcValue(pattern[i+3:end]))
+						i = end
+						break
+					}
+				}
+				msg = append(msg, pattern[i])
+			case 'x': // trace id only, the span id stays in the fields
+				msg = p.appendValue(msg, fieldString(e.Fields, TraceIDKey))
+				i++
+			case 'M':
+				msg = p.appendValue(msg, funcName(e.Caller))
+				i++
+			case 'L', 'l':
+				caller := string(appendFileLine(nil, e.Caller, pattern[i+1] == 'l'))
+				if p.TrimPathPrefix != "" && pattern[i+1] == 'L' && strings.HasPrefix(caller, p.TrimPathPrefix) {
+					caller = strings.TrimLeft(caller[len(p.TrimPathPrefix):], "/")
+				}
+				msg = p.appendValue(msg, caller)
 				i++
 			case '%':
 				msg = append(msg, '%')
@@ -168,32 +413,385 @@ func (p *PatternFormatter) Format(e *Entry) []byte {
 	return msg
 }
 
+func fieldString(fields map[string]interface{}, key string) string {
+	v, ok := fields[key]
+	if !ok || v == nil {
+		return ""
+	}
+	if s, ok := v.(string); ok {
+		return s
+	}
+	return fmt.Sprint(v)
+}
+
+func appendWidth(b []byte, s string, width int) []byte {
+	start := len(b)
+	return fitWidth(append(b, s...), start, width)
+}
+
+// fitWidth pads b[start:] with spaces or truncates it to width bytes, without splitting a rune.
+func fitWidth(b []byte, start int, width int) []byte {
+	if width <= 0 {
+		return b
+	}
+	end := start + width
+	if len(b) > end {
+		for end > start && !utf8.RuneStart(b[end]) {
+			end--
+		}
+		b = b[:end]
+	}
+	for len(b) < start+width {
+		b = append(b, ' ')
+	}
+	return b
+}
+
+// lastSegments returns the last n dot separated segments of tag.
+func lastSegments(tag string, n int) string {
+	for i := len(tag) - 1; i >= 0; i-- {
+		if tag[i] == '.' {
+			n--
+			if n == 0 {
+				return tag[i+1:]
+			}
+		}
+	}
+	return tag
+}
+
+func (p *PatternFormatter) appendValue(b []byte, v string) []byte {
+	if v == "" && p.FillEmpty {
+		if p.EmptyToken == "" {
+			return append(b, '-')
+		}
+		return append(b, p.EmptyToken...)
+	}
+	return append(b, v...)
+}
+
+// EntryWriter is implemented by writers that need the entry behind a line, such as
+// level or field aware sinks. Formatted entries are passed to WriteEntry along with
+// their formatted bytes p, raw writes through Logger.Write still go to Write.
+type EntryWriter interface {
+	io.Writer
+	WriteEntry(e *Entry, p []byte) (int, error)
+}
+
 type InternalLogger struct {
+	stats     stats
 	level     Level
 	formatter LogFormatter
 	writer    io.Writer
 	lock      sync.Mutex
+	loggers   sync.Map
+
+	levelFormatters map[Level]LogFormatter
+	fields          map[string]interface{} // added to every entry, under the entry's own fields
+	hooks           []Hook
+
+	writeTimeout time.Duration
+	writerGid    uint64 // goroutine inside writeTo, 0 when idle, see reentrant
+	stalled      int32  // 1 while a timed out write is still running, see output
+	seq          uint64 // last Entry.Seq, under lock
+	strict       bool
+	onError      func(error)
+
+	recoverMaxFrames int // see SetRecoverMaxFrames
+}
+
+// Hook is called with every entry of a root logger and of the loggers sharing it, once the
+// line is written, for example to forward errors to an alerting service. It runs on the
+// logging goroutine with the logger locked: it must not log through it nor keep e.
+type Hook func(e *Entry)
+
+func (l *InternalLogger) enabled(level Level) bool {
+	return level >= l.level
 }
 
-func (l *InternalLogger) formatWrite(e *Entry) (int, error) {
-	if e.Level < l.level {
+func (l *InternalLogger) formatWrite(e *Entry, prefix string, suffix string, filter func(*Entry) bool) (int, error) {
+	if !l.enabled(e.Level) {
 		return 0, nil
 	}
+	if filter != nil && !filter(e) {
+		return 0, nil
+	}
+	if l.reentrant() {
+		return fprint(os.Stderr, e.Tag, strings.TrimSpace(e.Level.String()), "%s", e.Message)
+	}
 	l.lock.Lock()
 	defer l.lock.Unlock()
-	p := l.formatter.Format(e)
-	return l.writer.Write(p)
+	if e.Time.IsZero() {
+		e.Time = time.Now()
+	}
+	l.seq++
+	e.Seq = l.seq
+	if len(l.fields) > 0 {
+		if len(e.Fields) == 0 {
+			e.Fields = l.fields
+		} else {
+			e.Fields = mergeFields(l.fields, e.Fields)
+		}
+	}
+	formatter := l.formatter
+	if f, ok := l.levelFormatters[e.Level]; ok {
+		formatter = f
+	}
+	p := affix(formatter.Format(e), prefix, suffix)
+	n, err := l.output(e, p, nil)
+	for _, hook := range l.hooks {
+		hook(e)
+	}
+	return n, err
+}
+
+// affix puts prefix and suffix around the formatted line, inside its trailing newline.
+func affix(p []byte, prefix string, suffix string) []byte {
+	if prefix == "" && suffix == "" {
+		return p
+	}
+	body := bytes.TrimSuffix(p, []byte{'\n'})
+	b := make([]byte, 0, len(p)+len(prefix)+len(suffix))
+	b = append(b, prefix...)
+	b = append(b, body...)
+	b = append(b, suffix...)
+	if len(body) != len(p) {
+		b = append(b, '\n')
+	}
+	return b
 }
 
 func (l *InternalLogger) write(p []byte) (int, error) {
+	if l.reentrant() {
+		return fprint(os.Stderr, "Logger", "WARN", "%s", bytes.TrimSuffix(p, []byte{'\n'}))
+	}
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	return l.output(nil, p, nil)
+}
+
+func (l *InternalLogger) readFrom(r io.Reader) (int64, error) {
+	if _, ok := l.writer.(io.ReaderFrom); !ok || l.reentrant() {
+		return io.Copy(rawWriter{l}, r)
+	}
 	l.lock.Lock()
 	defer l.lock.Unlock()
-	return l.writer.Write(p)
+	n, err := l.output(nil, nil, r)
+	return int64(n), err
+}
+
+// rawWriter hides the ReadFrom method of the logger from io.Copy.
+type rawWriter struct {
+	l *InternalLogger
+}
+
+func (w rawWriter) Write(p []byte) (int, error) {
+	return w.l.write(p)
+}
+
+// writeTo writes p, formatted from e or raw when e is nil, or copies r to a writer that is
+// an io.ReaderFrom when r is set. Writes are serialized, a timed out one included, so a
+// single goroutine is inside at a time.
+func (l *InternalLogger) writeTo(e *Entry, p []byte, r io.Reader) (int, error) {
+	atomic.StoreUint64(&l.writerGid, gid())
+	defer atomic.StoreUint64(&l.writerGid, 0)
+	if r != nil {
+		n, err := l.writer.(io.ReaderFrom).ReadFrom(r)
+		return int(n), err
+	}
+	return writeEntry(l.writer, e, p)
+}
+
+// reentrant reports whether the calling goroutine is inside writeTo, which happens when
+// a writer logs back into the logger it writes for, for example to report its own errors.
+// Taking the lock again would deadlock, so such lines go to stderr instead. The goroutine
+// id is only read while a write is in flight.
+func (l *InternalLogger) reentrant() bool {
+	writer := atomic.LoadUint64(&l.writerGid)
+	return writer != 0 && writer == gid()
+}
+
+// writeEntry dispatches to WriteEntry when w is an EntryWriter and e is set, and to Write otherwise.
+func writeEntry(w io.Writer, e *Entry, p []byte) (int, error) {
+	if ew, ok := w.(EntryWriter); ok && e != nil {
+		return ew.WriteEntry(e, p)
+	}
+	return w.Write(p)
+}
+
+// output is writeTo, giving up after writeTimeout when it is set. A write that
+// timed out is counted as dropped but keeps running in the background, and until
+// it returns the following lines are dropped at once rather than handed to the
+// writer concurrently, so a stuck writer holds a single goroutine. The stall is
+// reported when it starts and when it ends, see stallChanged.
+func (l *InternalLogger) output(e *Entry, p []byte, r io.Reader) (int, error) {
+	if atomic.LoadInt32(&l.stalled) != 0 {
+		l.stats.drop()
+		return 0, ErrWriteTimeout
+	}
+	if l.writeTimeout <= 0 {
+		n, err := l.writeTo(e, p, r)
+		l.stats.written(n, err)
+		return n, err
+	}
+	type result struct {
+		n   int
+		err error
+	}
+	const (
+		pending int32 = iota
+		finished
+		abandoned
+	)
+	state := pending
+	done := make(chan result, 1)
+	reported := make(chan struct{}) // keeps the resumed report after the stalled one
+	go func() {
+		n, err := l.writeTo(e, p, r)
+		done <- result{n, err}
+		if !atomic.CompareAndSwapInt32(&state, pending, finished) {
+			<-reported
+			atomic.StoreInt32(&l.stalled, 0)
+			l.stallChanged(ErrWriteResumed)
+		}
+	}()
+	timer := time.NewTimer(l.writeTimeout)
+	defer timer.Stop()
+	select {
+	case r := <-done:
+		l.stats.written(r.n, r.err)
+		return r.n, r.err
+	case <-timer.C:
+		atomic.StoreInt32(&l.stalled, 1)
+		if !atomic.CompareAndSwapInt32(&state, pending, abandoned) {
+			// The write returned as the timer fired.
+			atomic.StoreInt32(&l.stalled, 0)
+			r := <-done
+			l.stats.written(r.n, r.err)
+			return r.n, r.err
+		}
+		l.stats.drop()
+		go func() {
+			l.stallChanged(ErrWriteStalled)
+			close(reported)
+		}()
+		return 0, ErrWriteTimeout
+	}
+}
+
+// stallChanged reports ErrWriteStalled or ErrWriteResumed on the diagnostics output and to
+// the SetOnError function. It runs on its own goroutine, the logger may be locked.
+func (l *InternalLogger) stallChanged(err error) {
+	l.lock.Lock()
+	onError := l.onError
+	l.lock.Unlock()
+	print("Logger", "WARN", "%v", err)
+	if onError != nil {
+		onError(err)
+	}
 }
 
 type Logger struct {
 	tag            string
 	internalLogger *InternalLogger
+	callerSkip     int
+	prefix         string
+	suffix         string
+	fields         map[string]interface{}
+	filter         func(*Entry) bool
+	overflow       OverflowPolicy
+}
+
+func (l *Logger) emit(e *Entry) (int, error) {
+	e.overflow = l.overflow
+	return l.internalLogger.formatWrite(e, l.prefix, l.suffix, l.filter)
+}
+
+func (l *Logger) clone() *Logger {
+	c := *l
+	c.callerSkip = 0
+	return &c
+}
+
+// WithPrefix returns a logger that puts s at the start of every formatted line.
+func (l *Logger) WithPrefix(s string) *Logger {
+	c := l.clone()
+	c.prefix = l.prefix + s
+	return c
+}
+
+// WithSuffix returns a logger that puts s at the end of every formatted line, before the newline.
+func (l *Logger) WithSuffix(s string) *Logger {
+	c := l.clone()
+	c.suffix = l.suffix + s
+	return c
+}
+
+// Named returns a logger whose tag has name appended as a dotted segment, such as "db.pool".
+func (l *Logger) Named(name string) *Logger {
+	c := l.clone()
+	if l.tag == "" {
+		c.tag = name
+	} else {
+		c.tag = l.tag + "." + name
+	}
+	return c
+}
+
+// WithFields returns a logger that adds fields to every entry, over the fields it already has.
+func (l *Logger) WithFields(fields map[string]interface{}) *Logger {
+	c := l.clone()
+	c.fields = mergeFields(l.fields, fields)
+	return c
+}
+
+// With is WithFields taking alternating keys and values: l.With("user", id, "attempt", n).
+// A dangling key or a key that is not a string is dropped with a warning logged through l.
+func (l *Logger) With(kv ...interface{}) *Logger {
+	fields := make(map[string]interface{}, len(kv)/2)
+	for i := 0; i < len(kv); i += 2 {
+		if i+1 == len(kv) {
+			l.logf(1, WarnLevel, "With: dropped key %v without value", []interface{}{kv[i]})
+			break
+		}
+		key, ok := kv[i].(string)
+		if !ok {
+			l.logf(1, WarnLevel, "With: dropped non-string key %v (%T)", []interface{}{kv[i], kv[i]})
+			continue
+		}
+		fields[key] = kv[i+1]
+	}
+	return l.WithFields(fields)
+}
+
+// WithFilter returns a logger that only writes the entries for which fn returns true, fn
+// being called after the level check, for example to log a single user id. The filters of
+// l still apply.
+func (l *Logger) WithFilter(fn func(*Entry) bool) *Logger {
+	c := l.clone()
+	if parent := l.filter; parent != nil {
+		c.filter = func(e *Entry) bool {
+			return parent(e) && fn(e)
+		}
+	} else {
+		c.filter = fn
+	}
+	return c
+}
+
+// WithOverflowPolicy returns a logger whose entries are handled with p when they reach an
+// AsyncWriter with a full queue, directly or through a MultiSink. Block is the default.
+func (l *Logger) WithOverflowPolicy(p OverflowPolicy) *Logger {
+	c := l.clone()
+	c.overflow = p
+	return c
+}
+
+// Sub returns a logger for a subsystem, combining Named and WithFields.
+func (l *Logger) Sub(name string, fields map[string]interface{}) *Logger {
+	c := l.Named(name)
+	c.fields = mergeFields(l.fields, fields)
+	return c
 }
 
 func (l *Logger) Printf(format string, arr ...interface{}) (int, error) {
@@ -205,49 +803,122 @@ func (l *Logger) Write(p []byte) (n int, err error) {
 	return l.internalLogger.write(p)
 }
 
-func (l *Logger) DEBUG(format string, arr ...interface{}) {
-	arr, err := splitError(arr...)
-	msg := fmt.Sprintf(format, arr...)
-	if err != nil {
-		msg = fmt.Sprintf("%s %v", msg, err)
+// WriteRaw writes p unformatted like Write, adding a trailing newline when p has none so
+// consecutive raw writes stay on separate lines. Newlines inside p still split it into
+// several lines.
+func (l *Logger) WriteRaw(p []byte) (int, error) {
+	if len(p) == 0 || p[len(p)-1] != '\n' {
+		b := make([]byte, len(p)+1)
+		copy(b, p)
+		b[len(p)] = '\n'
+		p = b
+	}
+	return l.internalLogger.write(p)
+}
+
+// ReadFrom copies r unformatted like Write, so io.Copy(l, r) takes the fast path of the
+// underlying writer when it implements io.ReaderFrom. The logger stays locked until r is
+// drained and the write timeout does not apply, so do not copy readers that block for long.
+func (l *Logger) ReadFrom(r io.Reader) (int64, error) {
+	return l.internalLogger.readFrom(r)
+}
+
+func (l *Logger) WriterAt(level Level) io.Writer {
+	return &levelWriter{
+		logger: l,
+		level:  level,
 	}
+}
+
+type levelWriter struct {
+	logger *Logger
+	level  Level
+	strict bool // also hand write errors to the strict mode, for callers that ignore them
+}
+
+func (w *levelWriter) Write(p []byte) (int, error) {
 	entry := &Entry{
-		Tag:     l.tag,
-		Level:   DebugLevel,
-		Message: msg,
+		Tag:     w.logger.tag,
+		Level:   w.level,
+		Message: strings.TrimSuffix(string(p), "\n"),
+		Fields:  w.logger.fields,
+	}
+	if _, err := w.logger.emit(entry); err != nil {
+		if w.strict {
+			w.logger.internalLogger.failed(err)
+		}
+		return 0, err
 	}
-	l.internalLogger.formatWrite(entry)
+	return len(p), nil
+}
+
+func (l *Logger) DEBUG(format string, arr ...interface{}) {
+	l.logf(1, DebugLevel, format, arr)
 }
 
 func (l *Logger) INFO(format string, arr ...interface{}) {
-	arr, err := splitError(arr...)
-	msg := fmt.Sprintf(format, arr...)
-	if err != nil {
-		msg = fmt.Sprintf("%s %v", msg, err)
+	l.logf(1, InfoLevel, format, arr)
+}
+
+func (l *Logger) WARN(format string, arr ...interface{}) {
+	l.logf(1, WarnLevel, format, arr)
+}
+
+func (l *Logger) ERROR(format string, arr ...interface{}) {
+	l.logf(1, ErrorLevel, format, arr)
+}
+
+func (l *Logger) DebugIf(cond bool, format string, arr ...interface{}) {
+	if cond {
+		l.logf(1, DebugLevel, format, arr)
 	}
-	entry := &Entry{
-		Tag:     l.tag,
-		Level:   InfoLevel,
-		Message: msg,
+}
+
+func (l *Logger) InfoIf(cond bool, format string, arr ...interface{}) {
+	if cond {
+		l.logf(1, InfoLevel, format, arr)
 	}
-	l.internalLogger.formatWrite(entry)
 }
 
-func (l *Logger) WARN(format string, arr ...interface{}) {
-	arr, err := splitError(arr...)
-	msg := fmt.Sprintf(format, arr...)
-	if err != nil {
-		msg = fmt.Sprintf("%s %v", msg, err)
+func (l *Logger) WarnIf(cond bool, format string, arr ...interface{}) {
+	if cond {
+		l.logf(1, WarnLevel, format, arr)
 	}
-	entry := &Entry{
-		Tag:     l.tag,
-		Level:   WarnLevel,
-		Message: msg,
+}
+
+func (l *Logger) ErrorIf(cond bool, format string, arr ...interface{}) {
+	if cond {
+		l.logf(1, ErrorLevel, format, arr)
 	}
-	l.internalLogger.formatWrite(entry)
 }
 
-func (l *Logger) ERROR(format string, arr ...interface{}) {
+// Log logs at level, for levels computed at runtime such as WARN on retry and ERROR on
+// the final attempt. Unlike FATAL it never exits.
+func (l *Logger) Log(level Level, format string, arr ...interface{}) {
+	l.logf(1, level, format, arr)
+}
+
+// Timer returns a function that logs msg with the time elapsed since Timer was called,
+// at INFO level: defer l.Timer("handler")().
+func (l *Logger) Timer(msg string) func() {
+	return l.TimerAt(InfoLevel, msg)
+}
+
+// TimerAt is Timer at the given level.
+func (l *Logger) TimerAt(level Level, msg string) func() {
+	start := time.Now()
+	return func() {
+		if l.internalLogger.enabled(level) {
+			l.logf(1, level, "%s took %v", []interface{}{msg, time.Since(start)})
+		}
+	}
+}
+
+// logf formats and writes an entry, skip is the number of frames between the logging call and logf.
+func (l *Logger) logf(skip int, level Level, format string, arr []interface{}) {
+	if !l.internalLogger.enabled(level) {
+		return
+	}
 	arr, err := splitError(arr...)
 	msg := fmt.Sprintf(format, arr...)
 	if err != nil {
@@ -255,10 +926,153 @@ func (l *Logger) ERROR(format string, arr ...interface{}) {
 	}
 	entry := &Entry{
 		Tag:     l.tag,
-		Level:   ErrorLevel,
+		Level:   level,
 		Message: msg,
+		Caller:  callerPC(skip + 1 + l.callerSkip),
+		Fields:  l.fields,
+	}
+	l.logEntry(entry)
+}
+
+// logEntry emits entry and hands a write error to the strict mode, for the level methods
+// and the adapters whose callers have no error to check.
+func (l *Logger) logEntry(entry *Entry) {
+	if _, err := l.emit(entry); err != nil {
+		l.internalLogger.failed(err)
+	}
+}
+
+// failed reports a write error of a level method in strict mode.
+func (l *InternalLogger) failed(err error) {
+	l.lock.Lock()
+	strict, onError := l.strict, l.onError
+	l.lock.Unlock()
+	if !strict {
+		return
+	}
+	if onError != nil {
+		onError(err)
+		return
+	}
+	panic(fmt.Sprintf("logger: write failed: %v", err))
+}
+
+// FATAL logs at FATAL level, flushes the writer and then calls ExitFunc(1).
+func (l *Logger) FATAL(format string, arr ...interface{}) {
+	l.logf(1, FatalLevel, format, arr)
+	l.exit(1)
+}
+
+// exit flushes the writer, so the fatal line is not lost in a queue or buffer, and then
+// calls ExitFunc.
+func (l *Logger) exit(code int) {
+	if err := l.Flush(); err != nil {
+		print("Logger", "ERROR", "Flush error: %v", err)
+	}
+	ExitFunc(code)
+}
+
+// SetWriteTimeout makes writes that take longer than d return ErrWriteTimeout and be
+// counted as dropped, so a blocked writer cannot stall every goroutine logging through
+// this logger. Lines may be lost or arrive late: while a timed out write is still
+// running, further lines are dropped without reaching the writer. 0 disables the timeout.
+func (l *Logger) SetWriteTimeout(d time.Duration) {
+	l.internalLogger.lock.Lock()
+	defer l.internalLogger.lock.Unlock()
+	l.internalLogger.writeTimeout = d
+}
+
+// SetStrict makes the level methods of the root logger, and of every logger sharing it,
+// panic when the line cannot be written, for deployments where a lost line is fatal.
+// SetOnError replaces the panic. The same goes for Recover, BufferedLogger.Flush and the
+// adapters whose callers cannot see write errors: GRPCLogger, StdLoggerAdapter, StdLogger
+// and RedirectStdLog.
+func (l *Logger) SetStrict(strict bool) {
+	l.internalLogger.lock.Lock()
+	defer l.internalLogger.lock.Unlock()
+	l.internalLogger.strict = strict
+}
+
+// SetOnError sets the function called with the write errors of strict mode instead of panicking.
+// With a write timeout it is also called, strict or not, with ErrWriteStalled and
+// ErrWriteResumed when a timed out write leaves the writer stalled and when it returns.
+func (l *Logger) SetOnError(fn func(error)) {
+	l.internalLogger.lock.Lock()
+	defer l.internalLogger.lock.Unlock()
+	l.internalLogger.onError = fn
+}
+
+// Barrier blocks until the entries logged before it are written by the writer of the root
+// logger, for example before answering a request whose log line must be on record. It
+// waits on writers that queue lines, such as AsyncWriter, directly or in a MultiSink, the
+// other writers have written the entries already.
+func (l *Logger) Barrier() error {
+	return barrier(l.internalLogger.writer)
+}
+
+func barrier(w io.Writer) error {
+	if b, ok := w.(interface{ Barrier() error }); ok {
+		return b.Barrier()
+	}
+	return nil
+}
+
+// Flush is Barrier, and also flushes a writer of the root logger with a Flush method, such
+// as a *bufio.Writer. Unlike Close it leaves the writer usable.
+func (l *Logger) Flush() error {
+	w := l.internalLogger.writer
+	if f, ok := w.(interface{ Flush() error }); ok {
+		l.internalLogger.lock.Lock()
+		defer l.internalLogger.lock.Unlock()
+		return f.Flush()
+	}
+	return barrier(w)
+}
+
+// Writer returns the writer of the root logger, for example to type assert it to
+// *RotateWriter. Writing to it directly bypasses the logger's lock.
+func (l *Logger) Writer() io.Writer {
+	return l.internalLogger.writer
+}
+
+// Close closes the writer of the root logger when it is an io.Closer, which flushes
+// writers such as AsyncWriter and HTTPWriter. Loggers sharing the writer must not be
+// used afterwards.
+func (l *Logger) Close() error {
+	l.internalLogger.lock.Lock()
+	defer l.internalLogger.lock.Unlock()
+	if c, ok := l.internalLogger.writer.(io.Closer); ok {
+		return c.Close()
 	}
-	l.internalLogger.formatWrite(entry)
+	return nil
+}
+
+// SetFormatter replaces the formatter of the root logger, which also affects
+// every logger sharing it through GetLogger.
+func (l *Logger) SetFormatter(formatter LogFormatter) {
+	l.internalLogger.lock.Lock()
+	defer l.internalLogger.lock.Unlock()
+	l.internalLogger.formatter = formatter
+}
+
+// SetLevelFormatter sets the formatter of entries at level, for example to print errors
+// with more context than routine lines. A nil formatter goes back to the default one.
+func (l *Logger) SetLevelFormatter(level Level, formatter LogFormatter) {
+	l.internalLogger.lock.Lock()
+	defer l.internalLogger.lock.Unlock()
+	if formatter == nil {
+		delete(l.internalLogger.levelFormatters, level)
+		return
+	}
+	if l.internalLogger.levelFormatters == nil {
+		l.internalLogger.levelFormatters = make(map[Level]LogFormatter)
+	}
+	l.internalLogger.levelFormatters[level] = formatter
+}
+
+// Stats returns the counters of the root logger, which are shared with all loggers derived from it.
+func (l *Logger) Stats() Stats {
+	return l.internalLogger.stats.snapshot()
 }
 
 func (l *Logger) Level() Level {
@@ -269,11 +1083,28 @@ func (l *Logger) Tag() string {
 	return l.tag
 }
 
+// GetLogger returns the logger for tag, loggers are cached on the root logger
+// so the same instance is shared by every caller asking for the same tag.
 func (l *Logger) GetLogger(tag string) *Logger {
-	return &Logger{
+	if logger, ok := l.internalLogger.loggers.Load(tag); ok {
+		return logger.(*Logger)
+	}
+	logger, _ := l.internalLogger.loggers.LoadOrStore(tag, &Logger{
 		tag:            tag,
 		internalLogger: l.internalLogger,
+	})
+	return logger.(*Logger)
+}
+
+func mergeFields(base map[string]interface{}, fields map[string]interface{}) map[string]interface{} {
+	merged := make(map[string]interface{}, len(base)+len(fields))
+	for k, v := range base {
+		merged[k] = v
 	}
+	for k, v := range fields {
+		merged[k] = v
+	}
+	return merged
 }
 
 func splitError(arr ...interface{}) ([]interface{}, error) {
@@ -289,6 +1120,59 @@ func splitError(arr ...interface{}) ([]interface{}, error) {
 	return arr, err
 }
 
+// callerPC returns the program counter of the caller skip frames above the function calling callerPC.
+func callerPC(skip int) uintptr {
+	pcs := [1]uintptr{}
+	if runtime.Callers(skip+2, pcs[:]) == 0 {
+		return 0
+	}
+	return pcs[0]
+}
+
+func appendFileLine(b []byte, pc uintptr, short bool) []byte {
+	if pc == 0 {
+		return b
+	}
+	frame, _ := runtime.CallersFrames([]uintptr{pc}).Next()
+	if frame.File == "" {
+		return b
+	}
+	file := frame.File
+	if short {
+		file = shortFile(file)
+	}
+	b = append(b, file...)
+	b = append(b, ':')
+	return strconv.AppendInt(b, int64(frame.Line), 10)
+}
+
+// funcName returns the function at pc without its import path, such as "main.run".
+// The symbol is resolved for every entry, which costs about as much as %L.
+func funcName(pc uintptr) string {
+	if pc == 0 {
+		return ""
+	}
+	frame, _ := runtime.CallersFrames([]uintptr{pc}).Next()
+	name := frame.Function
+	if i := strings.LastIndexByte(name, '/'); i >= 0 {
+		name = name[i+1:]
+	}
+	return name
+}
+
+// shortFile strips the directories of file, accepting both separators since the path
+// is recorded on the build machine, which may differ from the one running the program.
+func shortFile(file string) string {
+	if i := strings.LastIndexAny(file, `/\`); i >= 0 {
+		return file[i+1:]
+	}
+	return file
+}
+
+func sprintln(args ...interface{}) string {
+	return strings.TrimSuffix(fmt.Sprintln(args...), "\n")
+}
+
 func NewRotateRootLogger(level Level, filePath string, fileName string) *Logger {
 	rotateWriter, _ := NewRotateWriter(filePath, fileName)
 	logger := &InternalLogger{
@@ -325,22 +1209,77 @@ func xInit() {
 		if slevel == "" {
 			slevel = "INFO"
 		}
-		spath := os.Getenv("STELLA_LOGGER_PATH")
-		if spath == "" {
-			spath = "./logs"
-		}
-		sfile := os.Getenv("STELLA_LOGGER_FILE")
-		if sfile == "" {
-			sfile = "log.txt"
-		}
 		level := Parse(slevel)
-		rotateWriter, _ := NewRotateWriter(spath, sfile)
-		writer := io.MultiWriter(os.Stdout, rotateWriter)
+		rotateWriter, _ := NewConfigRotateWriter(envRotateConfig())
 
-		defaultRootLogger = NewRootLogger(level, &DefaultFormatter{}, writer)
+		defaultRootLogger = NewRootLogger(level, &DefaultFormatter{}, envWriter(rotateWriter))
+		defaultRootLogger.callerSkip = 1
 	})
 }
 
+func envRotateConfig() *RotateConfig {
+	spath := os.Getenv("STELLA_LOGGER_PATH")
+	if spath == "" {
+		spath = "./logs"
+	}
+	sfile := os.Getenv("STELLA_LOGGER_FILE")
+	if sfile == "" {
+		sfile = "log.txt"
+	}
+	config := defaultRotateConfig(spath, sfile)
+	if s := os.Getenv("STELLA_LOGGER_MAX_FILES"); s != "" {
+		if n, err := strconv.Atoi(s); err == nil && n >= 0 {
+			config.MaxFiles = n
+		} else {
+			print("Logger", "WARN", "Invalid STELLA_LOGGER_MAX_FILES %q, use default %d", s, config.MaxFiles)
+		}
+	}
+	if s := os.Getenv("STELLA_LOGGER_MAX_SIZE"); s != "" {
+		if n, err := ParseSize(s); err == nil && n > 0 {
+			config.MaxFileSize = n
+		} else {
+			print("Logger", "WARN", "Invalid STELLA_LOGGER_MAX_SIZE %q, use default %d", s, config.MaxFileSize)
+		}
+	}
+	if s := os.Getenv("STELLA_LOGGER_DAILY"); s != "" {
+		if b, err := strconv.ParseBool(s); err == nil {
+			config.Daily = b
+		} else {
+			print("Logger", "WARN", "Invalid STELLA_LOGGER_DAILY %q, use default %v", s, config.Daily)
+		}
+	}
+	return config
+}
+
+// envWriter returns the writer of the default logger: stdout and the rotate writer, or the
+// rotate writer alone when STELLA_LOGGER_STDOUT is false. Unlike io.MultiWriter, MultiSink
+// is an io.Closer, so Close on the default logger closes the file, while stdout, wrapped in
+// a RotateWriter, is left open.
+func envWriter(rotateWriter io.Writer) io.Writer {
+	stdout := true
+	if s := os.Getenv("STELLA_LOGGER_STDOUT"); s != "" {
+		if b, err := strconv.ParseBool(s); err == nil {
+			stdout = b
+		} else {
+			print("Logger", "WARN", "Invalid STELLA_LOGGER_STDOUT %q, use default %v", s, stdout)
+		}
+	}
+	if !stdout {
+		return rotateWriter
+	}
+	return NewMultiSink(Sink{Writer: NewFileRotateWriter(os.Stdout, nil)}, Sink{Writer: rotateWriter})
+}
+
+// SetDefaultFields adds fields, such as the service name or version, to every entry of the
+// default logger and the loggers returned by GetLogger. Fields set on an entry take precedence.
+func SetDefaultFields(fields map[string]interface{}) {
+	xInit()
+	l := defaultRootLogger.internalLogger
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	l.fields = mergeFields(nil, fields)
+}
+
 func DEBUG(format string, arr ...interface{}) {
 	xInit()
 	defaultRootLogger.DEBUG(format, arr...)
@@ -361,6 +1300,11 @@ func ERROR(format string, arr ...interface{}) {
 	defaultRootLogger.ERROR(format, arr...)
 }
 
+func FATAL(format string, arr ...interface{}) {
+	xInit()
+	defaultRootLogger.FATAL(format, arr...)
+}
+
 func GetLogger(name string) *Logger {
 	xInit()
 	return defaultRootLogger.GetLogger(name)
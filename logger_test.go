@@ -15,9 +15,19 @@
 package logger_test
 
 import (
+	"bufio"
+	"bytes"
+	"encoding/json"
 	"fmt"
+	"io"
+	"log"
 	"os"
+	"path"
+	"regexp"
+	"runtime"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/stella-go/logger"
 )
@@ -57,6 +67,161 @@ func TestWrite(t *testing.T) {
 	rootLogger.Write([]byte("1234567890123%s4567890123456789012"))
 }
 
+func TestClose(t *testing.T) {
+	buf := &lockedWriter{writer: &bytes.Buffer{}}
+	writer := logger.NewAsyncWriter(buf, 16)
+	rootLogger := logger.NewRootLogger(logger.InfoLevel, &NopFormatter{}, writer)
+	rootLogger.INFO("queued")
+	if err := rootLogger.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if s := buf.writer.(*bytes.Buffer).String(); s != "queued" {
+		t.Fatalf("unexpected output %q", s)
+	}
+}
+
+type CallbackWriter struct {
+	bytes.Buffer
+	callback func()
+}
+
+func (w *CallbackWriter) Write(p []byte) (int, error) {
+	if w.callback != nil {
+		w.callback()
+	}
+	return w.Buffer.Write(p)
+}
+
+func TestReentrantWrite(t *testing.T) {
+	writer := &CallbackWriter{}
+	rootLogger := logger.NewRootLogger(logger.InfoLevel, &NopFormatter{}, writer)
+	writer.callback = func() {
+		rootLogger.ERROR("write failed")
+		rootLogger.Write([]byte("raw"))
+	}
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		rootLogger.INFO("message")
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("reentrant write deadlocked")
+	}
+	if writer.String() != "message" {
+		t.Fatalf("unexpected output %q", writer.String())
+	}
+}
+
+// wideFrameRecursionBeforeLoggingBack grows a stack whose text form is well over 16 KiB.
+type wideFrameRecursionBeforeLoggingBack struct {
+	log func()
+}
+
+func (r *wideFrameRecursionBeforeLoggingBack) descendThroughWideFrames(depth, a, b, c, d, e, f, g int) {
+	if depth == 0 {
+		r.log()
+		return
+	}
+	r.descendThroughWideFrames(depth-1, a+1<<40, b+1<<40, c+1<<40, d+1<<40, e+1<<40, f+1<<40, g+1<<40)
+}
+
+func TestReentrantWriteDeepStack(t *testing.T) {
+	writer := &CallbackWriter{}
+	rootLogger := logger.NewRootLogger(logger.InfoLevel, &NopFormatter{}, writer)
+	recursion := &wideFrameRecursionBeforeLoggingBack{log: func() { rootLogger.ERROR("write failed") }}
+	writer.callback = func() { recursion.descendThroughWideFrames(5000, 0, 0, 0, 0, 0, 0, 0) }
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		rootLogger.INFO("message")
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("reentrant write from a deep stack deadlocked")
+	}
+	if writer.String() != "message" {
+		t.Fatalf("unexpected output %q", writer.String())
+	}
+}
+
+func TestWriteRaw(t *testing.T) {
+	buf := &bytes.Buffer{}
+	rootLogger := logger.NewRootLogger(logger.InfoLevel, &NopFormatter{}, buf)
+	rootLogger.WriteRaw([]byte("first"))
+	rootLogger.WriteRaw([]byte("second\n"))
+	rootLogger.WriteRaw([]byte("third"))
+
+	if buf.String() != "first\nsecond\nthird\n" {
+		t.Fatalf("unexpected output %q", buf.String())
+	}
+}
+
+type EntryRecorder struct {
+	bytes.Buffer
+	entries []*logger.Entry
+}
+
+func (r *EntryRecorder) WriteEntry(e *logger.Entry, p []byte) (int, error) {
+	r.entries = append(r.entries, e)
+	return r.Write(p)
+}
+
+func TestEntryWriter(t *testing.T) {
+	recorder := &EntryRecorder{}
+	rootLogger := logger.NewRootLogger(logger.InfoLevel, &NopFormatter{}, recorder)
+	rootLogger.WithFields(map[string]interface{}{"user": "bob"}).WARN("formatted")
+	rootLogger.Write([]byte("raw"))
+
+	if recorder.String() != "formattedraw" {
+		t.Fatalf("unexpected output %q", recorder.String())
+	}
+	if len(recorder.entries) != 1 || recorder.entries[0].Level != logger.WarnLevel || recorder.entries[0].Fields["user"] != "bob" {
+		t.Fatalf("unexpected entries %v", recorder.entries)
+	}
+}
+
+func TestReadFrom(t *testing.T) {
+	payload := strings.Repeat("0123456789abcdef", 64*1024)
+	for _, w := range []io.Writer{&bytes.Buffer{}, &lockedWriter{writer: io.Discard}} {
+		rootLogger := logger.NewRootLogger(logger.InfoLevel, &NopFormatter{}, w)
+		n, err := io.Copy(rootLogger, strings.NewReader(payload))
+		if err != nil || n != int64(len(payload)) {
+			t.Fatalf("unexpected copy result %d, %v", n, err)
+		}
+		if stats := rootLogger.Stats(); stats.Bytes != int64(len(payload)) {
+			t.Fatalf("unexpected bytes %d", stats.Bytes)
+		}
+	}
+}
+
+func BenchmarkReadFrom(b *testing.B) {
+	payload := bytes.Repeat([]byte("0123456789abcdef"), 1024*1024)
+	f, err := os.CreateTemp(b.TempDir(), "copy")
+	if err != nil {
+		b.Fatal(err)
+	}
+	defer f.Close()
+	src, err := os.CreateTemp(b.TempDir(), "src")
+	if err != nil {
+		b.Fatal(err)
+	}
+	defer src.Close()
+	src.Write(payload)
+	rootLogger := logger.NewRootLogger(logger.InfoLevel, &NopFormatter{}, f)
+	b.SetBytes(int64(len(payload)))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		src.Seek(0, io.SeekStart)
+		f.Seek(0, io.SeekStart)
+		if _, err := io.Copy(rootLogger, src); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
 type NopFormatter struct{}
 
 func (*NopFormatter) Format(e *logger.Entry) []byte {
@@ -136,3 +301,843 @@ func TestNewPatternFormatterLogger(t *testing.T) {
 	logger.WARN("12345678901234567890123456789012")
 	logger.ERROR("12345678901234567890123456789012", fmt.Errorf("this is an error"))
 }
+
+func TestDefaultFormatter(t *testing.T) {
+	entry := &logger.Entry{
+		Tag:     "Test",
+		Level:   logger.InfoLevel,
+		Message: "This is a test message",
+	}
+	formatted := string((&logger.DefaultFormatter{}).Format(entry))
+	pattern := regexp.MustCompile(`^\d{2}-\d{2}-\d{2}\.\d{2}:\d{2}:\d{2}\.\d{3} \[ *\d{1,}\] INFO  Test - This is a test message\n$`)
+	if !pattern.MatchString(formatted) {
+		t.Fatalf("unexpected format %q", formatted)
+	}
+}
+
+func TestDefaultFormatterFixedGoroutine(t *testing.T) {
+	defer logger.SetGidFunc(func() uint64 { return 7 })()
+	entry := &logger.Entry{
+		Tag:     "Test",
+		Level:   logger.InfoLevel,
+		Message: "msg",
+		Time:    time.Date(2025, 6, 1, 12, 30, 45, 0, time.UTC),
+	}
+	formatted := string((&logger.DefaultFormatter{Location: time.UTC}).Format(entry))
+	if formatted != "25-06-01.12:30:45.000 [   7] INFO  Test - msg\n" {
+		t.Fatalf("unexpected format %q", formatted)
+	}
+}
+
+func BenchmarkDefaultFormatter(b *testing.B) {
+	b.ReportAllocs()
+	formatter := &logger.DefaultFormatter{}
+	entry := &logger.Entry{
+		Tag:     "Bench",
+		Level:   logger.InfoLevel,
+		Message: "12345678901234567890123456789012",
+	}
+	for i := 0; i < b.N; i++ {
+		formatter.Format(entry)
+	}
+}
+
+func BenchmarkDefaultFormatterHideGoroutine(b *testing.B) {
+	b.ReportAllocs()
+	formatter := &logger.DefaultFormatter{HideGoroutine: true}
+	entry := &logger.Entry{
+		Tag:     "Bench",
+		Level:   logger.InfoLevel,
+		Message: "12345678901234567890123456789012",
+	}
+	for i := 0; i < b.N; i++ {
+		formatter.Format(entry)
+	}
+}
+
+func TestDateWidth(t *testing.T) {
+	times := []time.Time{
+		time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC),
+		time.Date(2025, 12, 31, 23, 59, 59, 999999999, time.UTC),
+		time.Date(2099, 6, 1, 12, 0, 0, 1000000, time.UTC),
+	}
+	expected := []string{"25-01-02.03:04:05.000", "25-12-31.23:59:59.999", "99-06-01.12:00:00.001"}
+	for i, tm := range times {
+		entry := &logger.Entry{Tag: "Test", Level: logger.InfoLevel, Message: "msg", Time: tm}
+		formatted := string((&logger.DefaultFormatter{Location: time.UTC, HideGoroutine: true}).Format(entry))
+		if formatted != expected[i]+" INFO  Test - msg\n" {
+			t.Fatalf("unexpected format %q", formatted)
+		}
+		formatted = string((&logger.PatternFormatter{Pattern: "%d|%d", Location: time.UTC}).Format(entry))
+		if formatted != expected[i]+"|"+expected[i]+"\n" {
+			t.Fatalf("unexpected pattern format %q", formatted)
+		}
+	}
+}
+
+func TestDefaultFormatterHideGoroutine(t *testing.T) {
+	entry := &logger.Entry{Tag: "Test", Level: logger.InfoLevel, Message: "msg"}
+	formatted := string((&logger.DefaultFormatter{HideGoroutine: true}).Format(entry))
+	if !regexp.MustCompile(`^\d{2}-\d{2}-\d{2}\.\d{2}:\d{2}:\d{2}\.\d{3}0* INFO  Test - msg\n$`).MatchString(formatted) {
+		t.Fatalf("unexpected format %q", formatted)
+	}
+}
+
+func TestAllLevels(t *testing.T) {
+	levels := logger.AllLevels()
+	if len(levels) != 7 || levels[0] != logger.TraceLevel || levels[6] != logger.PanicLevel {
+		t.Fatalf("unexpected levels %v", levels)
+	}
+	for i := 1; i < len(levels); i++ {
+		if levels[i] <= levels[i-1] {
+			t.Fatalf("levels are not ordered: %v", levels)
+		}
+		if logger.Parse(levels[i].String()) != levels[i] {
+			t.Fatalf("level %v does not round trip", levels[i])
+		}
+	}
+	if !logger.WarnLevel.Enabled(logger.InfoLevel) || !logger.InfoLevel.Enabled(logger.InfoLevel) || logger.DebugLevel.Enabled(logger.InfoLevel) {
+		t.Fatal("unexpected Enabled result")
+	}
+}
+
+func TestPatternFormatterFillEmpty(t *testing.T) {
+	entry := &logger.Entry{
+		Level: logger.InfoLevel,
+	}
+	formatter := &logger.PatternFormatter{Pattern: "%p [%c] %m"}
+	if formatted := string(formatter.Format(entry)); formatted != "INFO  [] \n" {
+		t.Fatalf("unexpected format %q", formatted)
+	}
+	formatter = &logger.PatternFormatter{Pattern: "%p [%c] %m", FillEmpty: true}
+	if formatted := string(formatter.Format(entry)); formatted != "INFO  [-] -\n" {
+		t.Fatalf("unexpected format %q", formatted)
+	}
+	formatter = &logger.PatternFormatter{Pattern: "%p [%c] %m", FillEmpty: true, EmptyToken: "N/A"}
+	if formatted := string(formatter.Format(entry)); formatted != "INFO  [N/A] N/A\n" {
+		t.Fatalf("unexpected format %q", formatted)
+	}
+}
+
+func TestEnvRotateConfig(t *testing.T) {
+	config := logger.EnvRotateConfig()
+	if !config.Daily || config.MaxFiles != 31 || config.MaxFileSize != 200*logger.FileSizeM {
+		t.Fatalf("unexpected default config %+v", config)
+	}
+
+	os.Setenv("STELLA_LOGGER_MAX_FILES", "7")
+	os.Setenv("STELLA_LOGGER_MAX_SIZE", "1G")
+	os.Setenv("STELLA_LOGGER_DAILY", "false")
+	config = logger.EnvRotateConfig()
+	if config.Daily || config.MaxFiles != 7 || config.MaxFileSize != logger.FileSizeG {
+		t.Fatalf("unexpected config %+v", config)
+	}
+
+	os.Setenv("STELLA_LOGGER_MAX_FILES", "0")
+	if config = logger.EnvRotateConfig(); config.MaxFiles != 0 {
+		t.Fatalf("expected 0 to keep every archive, got %d", config.MaxFiles)
+	}
+
+	os.Setenv("STELLA_LOGGER_MAX_FILES", "many")
+	os.Setenv("STELLA_LOGGER_MAX_SIZE", "200X")
+	os.Setenv("STELLA_LOGGER_DAILY", "sometimes")
+	config = logger.EnvRotateConfig()
+	if !config.Daily || config.MaxFiles != 31 || config.MaxFileSize != 200*logger.FileSizeM {
+		t.Fatalf("unexpected fallback config %+v", config)
+	}
+
+	os.Unsetenv("STELLA_LOGGER_MAX_FILES")
+	os.Unsetenv("STELLA_LOGGER_MAX_SIZE")
+	os.Unsetenv("STELLA_LOGGER_DAILY")
+}
+
+func TestEnvWriter(t *testing.T) {
+	buf := &bytes.Buffer{}
+	if _, ok := logger.EnvWriter(buf).(*logger.MultiSink); !ok {
+		t.Fatal("stdout should be included by default")
+	}
+
+	// closing the default writer closes the file but leaves stdout open
+	file := &ClosingBuffer{lockedWriter: lockedWriter{writer: buf}}
+	if err := logger.EnvWriter(file).(io.Closer).Close(); err != nil || !file.closed {
+		t.Fatalf("the rotate writer was not closed: %v", err)
+	}
+	if _, err := os.Stdout.Stat(); err != nil {
+		t.Fatalf("stdout was closed: %v", err)
+	}
+
+	os.Setenv("STELLA_LOGGER_STDOUT", "false")
+	defer os.Unsetenv("STELLA_LOGGER_STDOUT")
+	if w := logger.EnvWriter(buf); w != buf {
+		t.Fatalf("unexpected writer %T", w)
+	}
+
+	os.Setenv("STELLA_LOGGER_STDOUT", "sometimes")
+	if _, ok := logger.EnvWriter(buf).(*logger.MultiSink); !ok {
+		t.Fatal("stdout should be included on invalid values")
+	}
+}
+
+func TestFlush(t *testing.T) {
+	buf := &bytes.Buffer{}
+	rootLogger := logger.NewRootLogger(logger.InfoLevel, &NopFormatter{}, bufio.NewWriter(buf))
+	rootLogger.INFO("buffered")
+	if buf.Len() != 0 {
+		t.Fatalf("unexpected output before Flush %q", buf.String())
+	}
+	if err := rootLogger.Flush(); err != nil || buf.String() != "buffered" {
+		t.Fatalf("unexpected output %q: %v", buf.String(), err)
+	}
+	rootLogger.INFO(" again")
+	if err := rootLogger.Flush(); err != nil || buf.String() != "buffered again" {
+		t.Fatalf("unexpected output %q: %v", buf.String(), err)
+	}
+}
+
+func TestGetLoggerCache(t *testing.T) {
+	rootLogger := logger.NewRootLogger(logger.InfoLevel, &NopFormatter{}, os.Stdout)
+	db := rootLogger.GetLogger("db")
+	if db != rootLogger.GetLogger("db") || db != db.GetLogger("db") {
+		t.Fatal("loggers with the same tag should be shared")
+	}
+	if db == rootLogger.GetLogger("http") || db.Tag() != "db" {
+		t.Fatal("loggers with different tags should differ")
+	}
+}
+
+func BenchmarkGetLogger(b *testing.B) {
+	b.ReportAllocs()
+	rootLogger := logger.NewRootLogger(logger.InfoLevel, &NopFormatter{}, os.Stdout)
+	for i := 0; i < b.N; i++ {
+		rootLogger.GetLogger("db")
+	}
+}
+
+func TestFormatterLocation(t *testing.T) {
+	entry := &logger.Entry{
+		Tag:     "Test",
+		Level:   logger.InfoLevel,
+		Message: "message",
+	}
+	zone := time.FixedZone("UTC+14", 14*60*60)
+	expected := time.Now().In(zone).Format("06-01-02.15")
+	formatted := string((&logger.DefaultFormatter{Location: zone}).Format(entry))
+	if !strings.HasPrefix(formatted, expected) {
+		t.Fatalf("unexpected timestamp %q, expected prefix %s", formatted, expected)
+	}
+	formatted = string((&logger.PatternFormatter{Pattern: "%d{06-01-02.15}", Location: zone}).Format(entry))
+	if formatted != expected+"\n" {
+		t.Fatalf("unexpected timestamp %q, expected %s", formatted, expected)
+	}
+}
+
+func TestCaller(t *testing.T) {
+	buf := &bytes.Buffer{}
+	rootLogger := logger.NewRootLogger(logger.InfoLevel, &logger.PatternFormatter{Pattern: "%l %L"}, buf)
+	restore := logger.SetDefaultRootLogger(rootLogger)
+	defer restore()
+
+	_, file, line, _ := runtime.Caller(0)
+	rootLogger.INFO("direct")
+	logger.INFO("through the package wrapper")
+	logger.NewStdLoggerAdapter(rootLogger, logger.InfoLevel).Print("through an adapter")
+
+	expected := fmt.Sprintf("logger_test.go:%d %s:%d\n", line+1, file, line+1) +
+		fmt.Sprintf("logger_test.go:%d %s:%d\n", line+2, file, line+2) +
+		fmt.Sprintf("logger_test.go:%d %s:%d\n", line+3, file, line+3)
+	if buf.String() != expected {
+		t.Fatalf("unexpected output:\n%s\nexpected:\n%s", buf.String(), expected)
+	}
+}
+
+func TestCallerTrimPathPrefix(t *testing.T) {
+	buf := &bytes.Buffer{}
+	_, file, line, _ := runtime.Caller(0)
+	dir := path.Dir(path.Dir(file))
+	rootLogger := logger.NewRootLogger(logger.InfoLevel, &logger.PatternFormatter{Pattern: "%L", TrimPathPrefix: dir}, buf)
+	rootLogger.INFO("trimmed")
+	rootLogger.SetFormatter(&logger.PatternFormatter{Pattern: "%L", TrimPathPrefix: "/elsewhere"})
+	rootLogger.INFO("kept")
+
+	expected := fmt.Sprintf("%s/logger_test.go:%d\n%s:%d\n", path.Base(path.Dir(file)), line+3, file, line+5)
+	if buf.String() != expected {
+		t.Fatalf("unexpected output:\n%s\nexpected:\n%s", buf.String(), expected)
+	}
+}
+
+func TestCallerFunction(t *testing.T) {
+	buf := &bytes.Buffer{}
+	rootLogger := logger.NewRootLogger(logger.InfoLevel, &logger.PatternFormatter{Pattern: "%M"}, buf)
+	rootLogger.INFO("direct")
+	func() {
+		rootLogger.INFO("closure")
+	}()
+
+	if buf.String() != "logger_test.TestCallerFunction\nlogger_test.TestCallerFunction.func1\n" {
+		t.Fatalf("unexpected output:\n%s", buf.String())
+	}
+}
+
+func TestPatternFormatterTagPrecision(t *testing.T) {
+	cases := map[string]string{
+		"%c":       "a.b.c",
+		"%c{1}":    "c",
+		"%c{2}":    "b.c",
+		"%c{3}":    "a.b.c",
+		"%c{9}":    "a.b.c",
+		"%c{0}":    "a.b.c{0}",
+		"%c{x} %m": "a.b.c{x} msg",
+		"%c{1 %m":  "a.b.c{1 msg",
+		"[%c{1}]":  "[c]",
+	}
+	entry := &logger.Entry{
+		Tag:     "a.b.c",
+		Level:   logger.InfoLevel,
+		Message: "msg",
+	}
+	for pattern, expected := range cases {
+		formatted := string((&logger.PatternFormatter{Pattern: pattern}).Format(entry))
+		if formatted != expected+"\n" {
+			t.Fatalf("pattern %q: unexpected output %q", pattern, formatted)
+		}
+	}
+}
+
+func TestTagWidth(t *testing.T) {
+	buf := &bytes.Buffer{}
+	rootLogger := logger.NewRootLogger(logger.InfoLevel, &logger.PatternFormatter{Pattern: "[%c] %m", TagWidth: 6}, buf)
+	rootLogger.GetLogger("db").INFO("short")
+	rootLogger.GetLogger("scheduler").INFO("long")
+
+	if buf.String() != "[db    ] short\n[schedu] long\n" {
+		t.Fatalf("unexpected output:\n%s", buf.String())
+	}
+
+	buf.Reset()
+	rootLogger.SetFormatter(&logger.DefaultFormatter{TagWidth: 6})
+	rootLogger.GetLogger("db").INFO("short")
+	rootLogger.GetLogger("scheduler").INFO("long")
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	if !strings.HasSuffix(lines[0], "INFO  db     - short") || !strings.HasSuffix(lines[1], "INFO  schedu - long") {
+		t.Fatalf("unexpected output:\n%s", buf.String())
+	}
+}
+
+func TestDisabledLevelAllocs(t *testing.T) {
+	rootLogger := logger.NewRootLogger(logger.InfoLevel, &logger.DefaultFormatter{}, &bytes.Buffer{})
+	allocs := testing.AllocsPerRun(100, func() {
+		rootLogger.DEBUG("12345678901234567890123456789012 %s %d", "abc", 42)
+	})
+	if allocs != 0 {
+		t.Fatalf("disabled level allocates %v times per call", allocs)
+	}
+}
+
+func BenchmarkLoggerDisabled(b *testing.B) {
+	b.ReportAllocs()
+	rootLogger := logger.NewRootLogger(logger.InfoLevel, &logger.DefaultFormatter{}, &bytes.Buffer{})
+	logger := rootLogger.GetLogger("Bench")
+	for i := 0; i < b.N; i++ {
+		logger.DEBUG("12345678901234567890123456789012")
+	}
+}
+
+func TestWithPrefixSuffix(t *testing.T) {
+	buf := &bytes.Buffer{}
+	rootLogger := logger.NewRootLogger(logger.InfoLevel, &logger.PatternFormatter{Pattern: "%p %c - %m"}, buf)
+	hostLogger := rootLogger.GetLogger("Host").WithPrefix("[web-1] ").WithSuffix(" env=prod").WithSuffix(" region=us")
+	hostLogger.INFO("started")
+	rootLogger.GetLogger("Host").INFO("plain")
+
+	expected := "[web-1] INFO  Host - started env=prod region=us\nINFO  Host - plain\n"
+	if buf.String() != expected {
+		t.Fatalf("unexpected output:\n%s", buf.String())
+	}
+}
+
+func TestSetFormatter(t *testing.T) {
+	buf := &bytes.Buffer{}
+	rootLogger := logger.NewRootLogger(logger.InfoLevel, &logger.PatternFormatter{Pattern: "%p %c - %m"}, buf)
+	childLogger := rootLogger.GetLogger("Child")
+	childLogger.INFO("before")
+	rootLogger.SetFormatter(&logger.PatternFormatter{Pattern: "%c|%m"})
+	childLogger.INFO("after")
+
+	if buf.String() != "INFO  Child - before\nChild|after\n" {
+		t.Fatalf("unexpected output:\n%s", buf.String())
+	}
+}
+
+func TestSetLevelFormatter(t *testing.T) {
+	buf := &bytes.Buffer{}
+	rootLogger := logger.NewRootLogger(logger.InfoLevel, &logger.PatternFormatter{Pattern: "%m"}, buf)
+	rootLogger.SetLevelFormatter(logger.ErrorLevel, &logger.PatternFormatter{Pattern: "%p %c - %m"})
+	rootLogger.INFO("compact")
+	rootLogger.ERROR("verbose")
+	rootLogger.SetLevelFormatter(logger.ErrorLevel, nil)
+	rootLogger.ERROR("reset")
+
+	if buf.String() != "compact\nERROR ROOT - verbose\nreset\n" {
+		t.Fatalf("unexpected output:\n%s", buf.String())
+	}
+}
+
+func TestPatternFormatterUnixTime(t *testing.T) {
+	formatter := &logger.PatternFormatter{Pattern: "%U %u %%u"}
+	e := &logger.Entry{Time: time.Unix(1700000000, 123456789)}
+	if s := string(formatter.Format(e)); s != "1700000000 1700000000123 %u\n" {
+		t.Fatalf("unexpected output %q", s)
+	}
+}
+
+func TestPatternFormatterDefaultDateLayout(t *testing.T) {
+	e := &logger.Entry{Time: time.Date(2025, 6, 1, 12, 30, 45, 123456789, time.UTC)}
+	formatter := &logger.PatternFormatter{Pattern: "%d|%d{15:04}", Location: time.UTC}
+	if s := string(formatter.Format(e)); s != "25-06-01.12:30:45.123|12:30\n" {
+		t.Fatalf("unexpected output %q", s)
+	}
+	formatter.DefaultDateLayout = "2006-01-02T15:04:05.000000Z07:00"
+	if s := string(formatter.Format(e)); s != "2025-06-01T12:30:45.123456Z|12:30\n" {
+		t.Fatalf("unexpected output %q", s)
+	}
+}
+
+func TestSequence(t *testing.T) {
+	buf := &bytes.Buffer{}
+	rootLogger := logger.NewRootLogger(logger.InfoLevel, &logger.PatternFormatter{Pattern: "%q %c"}, buf)
+	rootLogger.INFO("a")
+	rootLogger.DEBUG("skipped")
+	rootLogger.GetLogger("db").INFO("b")
+	rootLogger.WithFields(map[string]interface{}{"k": 1}).WARN("c")
+	if buf.String() != "1 ROOT\n2 db\n3 ROOT\n" {
+		t.Fatalf("unexpected output %q", buf.String())
+	}
+	buf.Reset()
+	rootLogger.SetFormatter(&logger.JSONFormatter{})
+	rootLogger.INFO("d")
+	if !strings.Contains(buf.String(), `"seq":4,`) {
+		t.Fatalf("unexpected output %q", buf.String())
+	}
+}
+
+func TestWithFilter(t *testing.T) {
+	buf := &bytes.Buffer{}
+	rootLogger := logger.NewRootLogger(logger.InfoLevel, &LineFormatter{}, buf)
+	calls := 0
+	byTag := rootLogger.WithFilter(func(e *logger.Entry) bool {
+		calls++
+		return !strings.HasSuffix(e.Tag, ".noisy")
+	})
+	byUser := byTag.WithFilter(func(e *logger.Entry) bool {
+		return strings.Contains(e.Message, "user=42")
+	})
+	byUser.INFO("login user=42")
+	byUser.INFO("login user=7")
+	byUser.DEBUG("below level user=42")
+	byUser.Named("noisy").INFO("poll user=42")
+	byTag.WARN("other user")
+
+	expected := "INFO  ROOT - login user=42\n" +
+		"WARN  ROOT - other user\n"
+	if buf.String() != expected {
+		t.Fatalf("unexpected output:\n%s", buf.String())
+	}
+	if calls != 4 {
+		t.Fatalf("unexpected filter calls %d", calls)
+	}
+}
+
+func TestStrict(t *testing.T) {
+	rootLogger := logger.NewRootLogger(logger.InfoLevel, &NopFormatter{}, &ErrorWriter{})
+	l := rootLogger.GetLogger("audit")
+	l.INFO("ignored")
+
+	rootLogger.SetStrict(true)
+	func() {
+		defer func() {
+			if r := recover(); r == nil || !strings.Contains(fmt.Sprint(r), "write failed") {
+				t.Fatalf("unexpected panic %v", r)
+			}
+		}()
+		l.ERROR("panics")
+	}()
+
+	var errs []error
+	rootLogger.SetOnError(func(err error) {
+		errs = append(errs, err)
+	})
+	l.WARN("reported")
+	l.DEBUG("below level")
+	if len(errs) != 1 || errs[0].Error() != "write failed" {
+		t.Fatalf("unexpected errors %v", errs)
+	}
+}
+
+func TestStrictAdapters(t *testing.T) {
+	rootLogger := logger.NewRootLogger(logger.InfoLevel, &NopFormatter{}, &ErrorWriter{})
+	rootLogger.SetStrict(true)
+	var errs []error
+	rootLogger.SetOnError(func(err error) {
+		errs = append(errs, err)
+	})
+	logger.NewGRPCLogger(rootLogger).Error("grpc")
+	logger.NewStdLoggerAdapter(rootLogger, logger.WarnLevel).Printf("adapter")
+	rootLogger.StdLogger(logger.InfoLevel).Print("std")
+	restore := logger.RedirectStdLog(rootLogger, logger.InfoLevel)
+	log.Print("redirected")
+	restore()
+	func() {
+		defer rootLogger.Recover()
+		panic("boom")
+	}()
+	if len(errs) != 5 {
+		t.Fatalf("unexpected errors %v", errs)
+	}
+
+	// a plain io.Writer returns the error to its caller instead
+	if _, err := rootLogger.WriterAt(logger.InfoLevel).Write([]byte("raw")); err == nil || len(errs) != 5 {
+		t.Fatalf("unexpected error %v, errors %v", err, errs)
+	}
+}
+
+func TestSetTimeLayout(t *testing.T) {
+	logger.SetTimeLayout(time.RFC3339Nano, time.UTC)
+	defer logger.SetTimeLayout("", nil)
+	e := &logger.Entry{Tag: "T", Level: logger.InfoLevel, Message: "m", Time: time.Date(2025, 6, 1, 12, 30, 45, 123456789, time.FixedZone("CEST", 2*3600))}
+	const ts = "2025-06-01T10:30:45.123456789Z"
+	formatters := []logger.LogFormatter{
+		&logger.DefaultFormatter{HideGoroutine: true},
+		&logger.PatternFormatter{Pattern: "%d"},
+		&logger.JSONFormatter{},
+		&logger.CSVFormatter{},
+	}
+	for _, f := range formatters {
+		if s := string(f.Format(e)); !strings.HasPrefix(s, ts) && !strings.HasPrefix(s, `{"time":"`+ts+`"`) {
+			t.Fatalf("%T: unexpected output %q", f, s)
+		}
+	}
+	own := &logger.PatternFormatter{Pattern: "%d", DefaultDateLayout: "15:04", Location: time.FixedZone("CEST", 2*3600)}
+	if s := string(own.Format(e)); s != "12:30\n" {
+		t.Fatalf("unexpected output %q", s)
+	}
+}
+
+func TestWith(t *testing.T) {
+	buf := &bytes.Buffer{}
+	rootLogger := logger.NewRootLogger(logger.InfoLevel, &logger.DefaultFormatter{HideGoroutine: true}, buf)
+	rootLogger.With("a", 1, "b", "two").With("a", 3).INFO("pairs")
+	if !strings.HasSuffix(buf.String(), "INFO  ROOT - pairs {a=3, b=two}\n") {
+		t.Fatalf("unexpected output %q", buf.String())
+	}
+	buf.Reset()
+
+	rootLogger.With("a", 1, 2, "x", "b", true, "dangling").INFO("derived")
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	if len(lines) != 3 ||
+		!strings.HasSuffix(lines[0], "WARN  ROOT - With: dropped non-string key 2 (int)") ||
+		!strings.HasSuffix(lines[1], "WARN  ROOT - With: dropped key dangling without value") ||
+		!strings.HasSuffix(lines[2], "INFO  ROOT - derived {a=1, b=true}") {
+		t.Fatalf("unexpected output:\n%s", buf.String())
+	}
+}
+
+func TestSub(t *testing.T) {
+	buf := &bytes.Buffer{}
+	rootLogger := logger.NewRootLogger(logger.InfoLevel, &logger.DefaultFormatter{}, buf)
+	dbLogger := rootLogger.GetLogger("db").WithFields(map[string]interface{}{"shard": 1})
+	poolLogger := dbLogger.Sub("pool", map[string]interface{}{"size": 10, "shard": 2})
+	poolLogger.INFO("opened")
+	dbLogger.INFO("ready")
+
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("unexpected output:\n%s", buf.String())
+	}
+	if !strings.HasSuffix(lines[0], "INFO  db.pool - opened {shard=2, size=10}") {
+		t.Fatalf("unexpected line %s", lines[0])
+	}
+	if !strings.HasSuffix(lines[1], "INFO  db - ready {shard=1}") {
+		t.Fatalf("unexpected line %s", lines[1])
+	}
+}
+
+func TestLog(t *testing.T) {
+	buf := &bytes.Buffer{}
+	rootLogger := logger.NewRootLogger(logger.TraceLevel, &logger.PatternFormatter{Pattern: "%p %m %l"}, buf)
+	_, _, line, _ := runtime.Caller(0)
+	expected := ""
+	for _, level := range logger.AllLevels() {
+		rootLogger.Log(level, "at %s", level.Short())
+		expected += fmt.Sprintf("%s at %s logger_test.go:%d\n", level, level.Short(), line+3)
+	}
+	rootLogger.Log(logger.ErrorLevel, "failed: ", fmt.Errorf("boom"))
+	expected += fmt.Sprintf("ERROR failed:  boom logger_test.go:%d\n", line+6)
+
+	if buf.String() != expected {
+		t.Fatalf("unexpected output:\n%s\nexpected:\n%s", buf.String(), expected)
+	}
+}
+
+func TestLevelMethods(t *testing.T) {
+	buf := &bytes.Buffer{}
+	rootLogger := logger.NewRootLogger(logger.DebugLevel, &logger.PatternFormatter{Pattern: "%p %m %l"}, buf)
+	logger.ExitFunc = func(int) {}
+	defer func() {
+		logger.ExitFunc = os.Exit
+	}()
+	_, _, line, _ := runtime.Caller(0)
+	rootLogger.DEBUG("debug %d", 1)
+	rootLogger.INFO("info %d", 2)
+	rootLogger.WARN("warn %d", 3)
+	rootLogger.ERROR("error ", fmt.Errorf("boom"))
+	rootLogger.FATAL("fatal")
+
+	expected := fmt.Sprintf("DEBUG debug 1 logger_test.go:%d\n", line+1) +
+		fmt.Sprintf("INFO  info 2 logger_test.go:%d\n", line+2) +
+		fmt.Sprintf("WARN  warn 3 logger_test.go:%d\n", line+3) +
+		fmt.Sprintf("ERROR error  boom logger_test.go:%d\n", line+4) +
+		fmt.Sprintf("FATAL fatal logger_test.go:%d\n", line+5)
+	if buf.String() != expected {
+		t.Fatalf("unexpected output:\n%s\nexpected:\n%s", buf.String(), expected)
+	}
+}
+
+func TestFatalFlushes(t *testing.T) {
+	buf := &bytes.Buffer{}
+	dest := &lockedWriter{writer: buf}
+	// without a flush the line would wait in the bufio.Writer for an hour
+	asyncWriter := logger.NewAsyncWriterFlush(bufio.NewWriter(dest), 16, time.Hour)
+	defer asyncWriter.Close()
+	rootLogger := logger.NewRootLogger(logger.InfoLevel, &LineFormatter{}, asyncWriter)
+	var output string
+	logger.ExitFunc = func(int) {
+		dest.lock.Lock()
+		defer dest.lock.Unlock()
+		output = buf.String()
+	}
+	defer func() {
+		logger.ExitFunc = os.Exit
+	}()
+	rootLogger.FATAL("cannot start")
+	if output != "FATAL ROOT - cannot start\n" {
+		t.Fatalf("unexpected output at exit %q", output)
+	}
+}
+
+func TestTimer(t *testing.T) {
+	buf := &bytes.Buffer{}
+	rootLogger := logger.NewRootLogger(logger.InfoLevel, &logger.PatternFormatter{Pattern: "%p %m %l"}, buf)
+	func() {
+		defer rootLogger.Timer("handler")()
+		time.Sleep(10 * time.Millisecond)
+	}()
+	rootLogger.TimerAt(logger.DebugLevel, "hidden")()
+
+	matched, _ := regexp.MatchString(`^INFO  handler took [\d.]+m?s logger_test.go:\d+\n$`, buf.String())
+	if !matched {
+		t.Fatalf("unexpected output %q", buf.String())
+	}
+}
+
+func TestSetDefaultFields(t *testing.T) {
+	buf := &bytes.Buffer{}
+	defer logger.SetDefaultRootLogger(logger.NewRootLogger(logger.InfoLevel, &logger.JSONFormatter{}, buf))()
+	logger.SetDefaultFields(map[string]interface{}{"service": "api", "version": "1.0"})
+	logger.INFO("root")
+	logger.GetLogger("db").WithFields(map[string]interface{}{"version": "2.0"}).INFO("child")
+
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("unexpected output:\n%s", buf.String())
+	}
+	if !strings.HasSuffix(lines[0], `"message":"root","fields":{"service":"api","version":"1.0"}}`) {
+		t.Fatalf("unexpected line %s", lines[0])
+	}
+	if !strings.HasSuffix(lines[1], `"message":"child","fields":{"service":"api","version":"2.0"}}`) {
+		t.Fatalf("unexpected line %s", lines[1])
+	}
+}
+
+func TestFatal(t *testing.T) {
+	buf := &bytes.Buffer{}
+	rootLogger := logger.NewRootLogger(logger.InfoLevel, &logger.PatternFormatter{Pattern: "%p %c - %m"}, buf)
+	code := -1
+	logger.ExitFunc = func(c int) {
+		code = c
+	}
+	defer func() {
+		logger.ExitFunc = os.Exit
+	}()
+	rootLogger.FATAL("cannot start: ", fmt.Errorf("port in use"))
+	if code != 1 || buf.String() != "FATAL ROOT - cannot start:  port in use\n" {
+		t.Fatalf("unexpected exit code %d, output %q", code, buf.String())
+	}
+
+	code = -1
+	logger.NewGRPCLogger(rootLogger).Fatalf("grpc %s", "failure")
+	if code != 1 {
+		t.Fatalf("unexpected exit code %d", code)
+	}
+}
+
+func TestConditionalLogging(t *testing.T) {
+	buf := &bytes.Buffer{}
+	rootLogger := logger.NewRootLogger(logger.DebugLevel, &logger.PatternFormatter{Pattern: "%p %m %l"}, buf)
+	_, _, line, _ := runtime.Caller(0)
+	rootLogger.DebugIf(true, "debug %d", 1)
+	rootLogger.InfoIf(false, "info %d", 2)
+	rootLogger.WarnIf(true, "warn %d", 3)
+	rootLogger.ErrorIf(false, "error %d", 4)
+
+	expected := fmt.Sprintf("DEBUG debug 1 logger_test.go:%d\nWARN  warn 3 logger_test.go:%d\n", line+1, line+3)
+	if buf.String() != expected {
+		t.Fatalf("unexpected output:\n%s", buf.String())
+	}
+	allocs := testing.AllocsPerRun(100, func() {
+		rootLogger.InfoIf(false, "slow request %s took %dms", "/api", 512)
+	})
+	if allocs != 0 {
+		t.Fatalf("false condition allocates %v times per call", allocs)
+	}
+}
+
+func ExampleLogger_InfoIf() {
+	rootLogger := logger.NewRootLogger(logger.InfoLevel, &logger.PatternFormatter{Pattern: "%p %m"}, os.Stdout)
+	for _, ms := range []int{120, 730} {
+		rootLogger.InfoIf(ms > 500, "slow request took %dms", ms)
+	}
+	// Output: INFO  slow request took 730ms
+}
+
+func ExampleLogger_ErrorIf() {
+	rootLogger := logger.NewRootLogger(logger.InfoLevel, &logger.PatternFormatter{Pattern: "%p %m"}, os.Stdout)
+	err := fmt.Errorf("connection reset")
+	rootLogger.ErrorIf(err != nil, "request failed: ", err)
+	// Output: ERROR request failed:  connection reset
+}
+
+func TestShortLevel(t *testing.T) {
+	shorts := make(map[string]logger.Level)
+	for _, level := range logger.AllLevels() {
+		short := level.Short()
+		if len(short) != 1 {
+			t.Fatalf("level %v has short name %q", level, short)
+		}
+		if other, ok := shorts[short]; ok {
+			t.Fatalf("levels %v and %v share short name %q", level, other, short)
+		}
+		shorts[short] = level
+	}
+	entry := &logger.Entry{
+		Tag:     "Test",
+		Level:   logger.WarnLevel,
+		Message: "message",
+	}
+	if formatted := string((&logger.PatternFormatter{Pattern: "%P %c %m"}).Format(entry)); formatted != "W Test message\n" {
+		t.Fatalf("unexpected format %q", formatted)
+	}
+}
+
+func TestShortFile(t *testing.T) {
+	cases := map[string]string{
+		"/home/bob/app/main.go":    "main.go",
+		`C:\Users\bob\app\main.go`: "main.go",
+		`C:/Users/bob\app/main.go`: "main.go",
+		"main.go":                  "main.go",
+	}
+	for file, expected := range cases {
+		if short := logger.ShortFile(file); short != expected {
+			t.Fatalf("%q: unexpected short file %q", file, short)
+		}
+	}
+}
+
+func TestAppendGoroutine(t *testing.T) {
+	cases := []struct {
+		id       uint64
+		width    int
+		label    bool
+		expected string
+	}{
+		{42, 0, false, "  42"},
+		{42, 6, false, "    42"},
+		{1234567, 0, false, "1234567"},
+		{1234567, 6, false, "1234567"},
+		{42, 0, true, "goroutine-42  "},
+		{1234567, 0, true, "goroutine-1234567"},
+	}
+	for _, c := range cases {
+		if s := string(logger.AppendGoroutine(nil, c.id, c.width, c.label)); s != c.expected {
+			t.Fatalf("%d width %d label %v: unexpected %q", c.id, c.width, c.label, s)
+		}
+	}
+}
+
+func TestParseGid(t *testing.T) {
+	cases := map[string]uint64{
+		"goroutine 42 [running]:\nmain.main()": 42,
+		"goroutine 7 [":                        7,
+		"goroutine 12345":                      0,
+		"goroutine x [running]":                0,
+		"":                                     0,
+		"garbage":                              0,
+	}
+	for stack, expected := range cases {
+		if n := logger.ParseGid([]byte(stack)); n != expected {
+			t.Fatalf("ParseGid(%q) = %d, expected %d", stack, n, expected)
+		}
+	}
+}
+
+func TestGoroutinePresentation(t *testing.T) {
+	stack := make([]byte, 64)
+	id := logger.ParseGid(stack[:runtime.Stack(stack, false)])
+	entry := &logger.Entry{Tag: "Test", Level: logger.InfoLevel, Message: "msg"}
+
+	text := string((&logger.DefaultFormatter{}).Format(entry))
+	if !strings.Contains(text, fmt.Sprintf("[%4d]", id)) {
+		t.Fatalf("unexpected text goroutine %q", text)
+	}
+	text = string((&logger.DefaultFormatter{GoroutineLabel: true}).Format(entry))
+	if !strings.Contains(text, fmt.Sprintf("[%-14s]", fmt.Sprintf("goroutine-%d", id))) {
+		t.Fatalf("unexpected labeled goroutine %q", text)
+	}
+	var out struct {
+		Goroutine uint64 `json:"goroutine"`
+	}
+	if err := json.Unmarshal((&logger.JSONFormatter{}).Format(entry), &out); err != nil {
+		t.Fatal(err)
+	}
+	if out.Goroutine != id {
+		t.Fatalf("unexpected structured goroutine %d, expected %d", out.Goroutine, id)
+	}
+}
+
+func TestDefaultFormatterFields(t *testing.T) {
+	entry := &logger.Entry{
+		Tag:     "Test",
+		Level:   logger.InfoLevel,
+		Message: "request done",
+		Fields:  map[string]interface{}{"status": 200, "path": "/api", "cost": 1.5},
+	}
+	formatted := string((&logger.DefaultFormatter{}).Format(entry))
+	if !strings.HasSuffix(formatted, "INFO  Test - request done {cost=1.5, path=/api, status=200}\n") {
+		t.Fatalf("unexpected format %q", formatted)
+	}
+	entry.Fields = map[string]interface{}{}
+	formatted = string((&logger.DefaultFormatter{}).Format(entry))
+	if !strings.HasSuffix(formatted, "INFO  Test - request done\n") {
+		t.Fatalf("unexpected format %q", formatted)
+	}
+}
+
+func TestWriter(t *testing.T) {
+	rootLogger := logger.NewRotateRootLogger(logger.InfoLevel, t.TempDir(), "stella-go.log")
+	writer, ok := rootLogger.GetLogger("Test").Writer().(*logger.RotateWriter)
+	if !ok {
+		t.Fatalf("unexpected writer %T", rootLogger.Writer())
+	}
+	if err := writer.Rotate(); err != nil {
+		t.Fatal(err)
+	}
+}
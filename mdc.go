@@ -0,0 +1,57 @@
+// Copyright 2010-2025 the original author or authors.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+
+// 	http://www.apache.org/licenses/LICENSE-2.0
+
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logger
+
+import "sync"
+
+// mdc maps a goroutine id to its context, each map is only touched by its own goroutine.
+var mdc sync.Map
+
+// PushMDC sets key to value in the mapped diagnostic context of the calling goroutine,
+// %X{key} renders it in a PatternFormatter. Goroutine ids are reused, so pair every
+// PushMDC with PopMDC, or call ClearMDC before a pooled worker goes back to the pool.
+func PushMDC(key string, value string) {
+	id := gid()
+	if m, ok := mdc.Load(id); ok {
+		m.(map[string]string)[key] = value
+		return
+	}
+	mdc.Store(id, map[string]string{key: value})
+}
+
+// PopMDC removes key from the context of the calling goroutine.
+func PopMDC(key string) {
+	id := gid()
+	m, ok := mdc.Load(id)
+	if !ok {
+		return
+	}
+	delete(m.(map[string]string), key)
+	if len(m.(map[string]string)) == 0 {
+		mdc.Delete(id)
+	}
+}
+
+// ClearMDC removes the whole context of the calling goroutine.
+func ClearMDC() {
+	mdc.Delete(gid())
+}
+
+func mdcValue(key string) string {
+	if m, ok := mdc.Load(gid()); ok {
+		return m.(map[string]string)[key]
+	}
+	return ""
+}
@@ -0,0 +1,49 @@
+// Copyright 2010-2025 the original author or authors.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+
+// 	http://www.apache.org/licenses/LICENSE-2.0
+
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logger_test
+
+import (
+	"bytes"
+	"sync"
+	"testing"
+
+	"github.com/stella-go/logger"
+)
+
+func TestMDC(t *testing.T) {
+	buf := &bytes.Buffer{}
+	rootLogger := logger.NewRootLogger(logger.InfoLevel, &logger.PatternFormatter{Pattern: "[%X{req}] %m %X"}, buf)
+
+	logger.PushMDC("req", "r-1")
+	rootLogger.INFO("pushed")
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		rootLogger.INFO("other")
+	}()
+	wg.Wait()
+	logger.PopMDC("req")
+	rootLogger.INFO("popped")
+
+	logger.PushMDC("req", "r-2")
+	logger.ClearMDC()
+	rootLogger.INFO("cleared")
+
+	want := "[r-1] pushed %X\n[] other %X\n[] popped %X\n[] cleared %X\n"
+	if buf.String() != want {
+		t.Fatalf("unexpected output:\n%s", buf.String())
+	}
+}
@@ -0,0 +1,93 @@
+// Copyright 2010-2025 the original author or authors.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+
+// 	http://www.apache.org/licenses/LICENSE-2.0
+
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logger
+
+import (
+	"io"
+)
+
+// Sink is a destination that receives the entries at or above Level. Formatter, when set,
+// replaces the logger's formatter for this sink, the logger's prefix and suffix are then
+// not applied.
+type Sink struct {
+	Writer    io.Writer
+	Level     Level
+	Formatter LogFormatter
+	// Filter, when set, further selects the levels the sink receives, such as
+	// func(l Level) bool { return l <= DebugLevel } for a debug file.
+	Filter func(Level) bool
+}
+
+// MultiSink routes each formatted entry to the sinks whose level it passes,
+// raw writes through Logger.Write go to every sink.
+type MultiSink struct {
+	sinks []Sink
+}
+
+func NewMultiSink(sinks ...Sink) *MultiSink {
+	return &MultiSink{
+		sinks: sinks,
+	}
+}
+
+func (m *MultiSink) Write(p []byte) (int, error) {
+	for _, sink := range m.sinks {
+		if _, err := sink.Writer.Write(p); err != nil {
+			return 0, err
+		}
+	}
+	return len(p), nil
+}
+
+func (m *MultiSink) WriteEntry(e *Entry, p []byte) (int, error) {
+	for _, sink := range m.sinks {
+		if e.Level < sink.Level || sink.Filter != nil && !sink.Filter(e.Level) {
+			continue
+		}
+		b := p
+		if sink.Formatter != nil {
+			b = sink.Formatter.Format(e)
+		}
+		if _, err := writeEntry(sink.Writer, e, b); err != nil {
+			return 0, err
+		}
+	}
+	return len(p), nil
+}
+
+// Barrier waits on the sink writers that queue lines, such as AsyncWriter, and returns
+// the first error.
+func (m *MultiSink) Barrier() error {
+	var err error
+	for _, sink := range m.sinks {
+		if e := barrier(sink.Writer); e != nil && err == nil {
+			err = e
+		}
+	}
+	return err
+}
+
+// Close closes the sink writers that are an io.Closer and returns the first error.
+func (m *MultiSink) Close() error {
+	var err error
+	for _, sink := range m.sinks {
+		if c, ok := sink.Writer.(io.Closer); ok {
+			if e := c.Close(); e != nil && err == nil {
+				err = e
+			}
+		}
+	}
+	return err
+}
@@ -0,0 +1,87 @@
+// Copyright 2010-2025 the original author or authors.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+
+// 	http://www.apache.org/licenses/LICENSE-2.0
+
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logger_test
+
+import (
+	"bytes"
+	"fmt"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/stella-go/logger"
+)
+
+func TestMultiSink(t *testing.T) {
+	all := &bytes.Buffer{}
+	errors := &bytes.Buffer{}
+	sink := logger.NewMultiSink(logger.Sink{Writer: all, Level: logger.DebugLevel}, logger.Sink{Writer: errors, Level: logger.ErrorLevel})
+	rootLogger := logger.NewRootLogger(logger.InfoLevel, &LineFormatter{}, sink)
+	rootLogger.DEBUG("hidden")
+	rootLogger.INFO("info")
+	rootLogger.ERROR("error")
+	rootLogger.Write([]byte("raw\n"))
+
+	if all.String() != "INFO  ROOT - info\nERROR ROOT - error\nraw\n" {
+		t.Fatalf("unexpected output:\n%s", all.String())
+	}
+	if errors.String() != "ERROR ROOT - error\nraw\n" {
+		t.Fatalf("unexpected error output:\n%s", errors.String())
+	}
+}
+
+func TestMultiSinkFilter(t *testing.T) {
+	debug := &bytes.Buffer{}
+	alerts := &bytes.Buffer{}
+	fatal := &bytes.Buffer{}
+	sink := logger.NewMultiSink(
+		logger.Sink{Writer: debug, Filter: func(l logger.Level) bool { return l <= logger.DebugLevel }},
+		logger.Sink{Writer: alerts, Level: logger.WarnLevel, Filter: func(l logger.Level) bool { return l != logger.FatalLevel }},
+		logger.Sink{Writer: fatal, Level: logger.ErrorLevel, Filter: func(l logger.Level) bool { return l >= logger.FatalLevel }},
+	)
+	rootLogger := logger.NewRootLogger(logger.TraceLevel, &LineFormatter{}, sink)
+	for _, level := range logger.AllLevels() {
+		rootLogger.Log(level, "%s", strings.TrimSpace(level.String()))
+	}
+
+	if debug.String() != "TRACE ROOT - TRACE\nDEBUG ROOT - DEBUG\n" {
+		t.Fatalf("unexpected debug output:\n%s", debug.String())
+	}
+	if alerts.String() != "WARN  ROOT - WARN\nERROR ROOT - ERROR\nPANIC ROOT - PANIC\n" {
+		t.Fatalf("unexpected alerts output:\n%s", alerts.String())
+	}
+	if fatal.String() != "FATAL ROOT - FATAL\nPANIC ROOT - PANIC\n" {
+		t.Fatalf("unexpected fatal output:\n%s", fatal.String())
+	}
+}
+
+func TestMultiSinkFormatters(t *testing.T) {
+	console := &bytes.Buffer{}
+	file := &bytes.Buffer{}
+	sink := logger.NewMultiSink(
+		logger.Sink{Writer: console, Formatter: logger.NewCompactFormatter()},
+		logger.Sink{Writer: file, Formatter: logger.NewVerboseFormatter()},
+	)
+	rootLogger := logger.NewRootLogger(logger.InfoLevel, &LineFormatter{}, sink)
+	_, _, line, _ := runtime.Caller(0)
+	rootLogger.WithFields(map[string]interface{}{"user": "bob"}).INFO("login")
+
+	if !strings.HasSuffix(console.String(), " INFO  ROOT - login\n") {
+		t.Fatalf("unexpected console output %q", console.String())
+	}
+	if !strings.HasSuffix(file.String(), fmt.Sprintf("] INFO  ROOT multi_sink_test.go:%d - login {user=bob}\n", line+1)) {
+		t.Fatalf("unexpected file output %q", file.String())
+	}
+}
@@ -0,0 +1,36 @@
+// Copyright 2010-2025 the original author or authors.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+
+// 	http://www.apache.org/licenses/LICENSE-2.0
+
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logger
+
+import "strings"
+
+// MultilineFormatter formats every line of a multi-line message as an entry of its own
+// with Formatter, so each line carries the timestamp, level and tag that parsers expect.
+type MultilineFormatter struct {
+	Formatter LogFormatter
+}
+
+func (f *MultilineFormatter) Format(e *Entry) []byte {
+	if !strings.Contains(e.Message, "\n") {
+		return f.Formatter.Format(e)
+	}
+	var msg []byte
+	line := *e
+	for _, s := range strings.Split(strings.TrimSuffix(e.Message, "\n"), "\n") {
+		line.Message = s
+		msg = append(msg, f.Formatter.Format(&line)...)
+	}
+	return msg
+}
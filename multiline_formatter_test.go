@@ -0,0 +1,33 @@
+// Copyright 2010-2025 the original author or authors.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+
+// 	http://www.apache.org/licenses/LICENSE-2.0
+
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logger_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stella-go/logger"
+)
+
+func TestMultilineFormatter(t *testing.T) {
+	buf := &bytes.Buffer{}
+	rootLogger := logger.NewRootLogger(logger.InfoLevel, &logger.MultilineFormatter{Formatter: &LineFormatter{}}, buf)
+	rootLogger.ERROR("first\nsecond\nthird")
+	rootLogger.INFO("single")
+
+	if buf.String() != "ERROR ROOT - first\nERROR ROOT - second\nERROR ROOT - third\nINFO  ROOT - single\n" {
+		t.Fatalf("unexpected output:\n%s", buf.String())
+	}
+}
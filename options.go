@@ -0,0 +1,75 @@
+// Copyright 2010-2025 the original author or authors.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+
+// 	http://www.apache.org/licenses/LICENSE-2.0
+
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logger
+
+import (
+	"io"
+	"os"
+)
+
+// Option configures NewLogger, options apply in order on top of the defaults of New.
+type Option func(b *Builder)
+
+func WithLevel(level Level) Option {
+	return func(b *Builder) {
+		b.Level(level)
+	}
+}
+
+func WithFormatter(formatter LogFormatter) Option {
+	return func(b *Builder) {
+		b.Formatter(formatter)
+	}
+}
+
+func WithWriter(writer io.Writer) Option {
+	return func(b *Builder) {
+		b.Writer(writer)
+	}
+}
+
+func WithRotation(config *RotateConfig) Option {
+	return func(b *Builder) {
+		b.Rotate(config)
+	}
+}
+
+func WithAsync(size int) Option {
+	return func(b *Builder) {
+		b.Async(size)
+	}
+}
+
+func WithHook(hook Hook) Option {
+	return func(b *Builder) {
+		b.Hook(hook)
+	}
+}
+
+// NewLogger creates a root logger, by default at InfoLevel with DefaultFormatter writing
+// to stdout. When the rotate writer cannot be opened it prints the error and writes to
+// stdout instead, use the Builder to handle the error.
+func NewLogger(opts ...Option) *Logger {
+	b := New()
+	for _, opt := range opts {
+		opt(b)
+	}
+	l, err := b.Build()
+	if err != nil {
+		print("Logger", "ERROR", "Open rotate writer error: %v, use stdout", err)
+		l, _ = b.Writer(os.Stdout).Build()
+	}
+	return l
+}
@@ -0,0 +1,77 @@
+// Copyright 2010-2025 the original author or authors.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+
+// 	http://www.apache.org/licenses/LICENSE-2.0
+
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logger_test
+
+import (
+	"bytes"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/stella-go/logger"
+)
+
+func TestNewLoggerDefaults(t *testing.T) {
+	rootLogger := logger.NewLogger()
+	if rootLogger.Level() != logger.InfoLevel || rootLogger.Writer() != os.Stdout {
+		t.Fatalf("unexpected defaults %v %v", rootLogger.Level(), rootLogger.Writer())
+	}
+}
+
+func TestWithHook(t *testing.T) {
+	var levels []logger.Level
+	rootLogger := logger.NewLogger(
+		logger.WithWriter(&bytes.Buffer{}),
+		logger.WithHook(func(e *logger.Entry) { levels = append(levels, e.Level) }),
+		logger.WithHook(func(e *logger.Entry) { levels = append(levels, e.Level+10) }),
+	)
+	rootLogger.DEBUG("hidden")
+	rootLogger.WARN("shown")
+	if len(levels) != 2 || levels[0] != logger.WarnLevel || levels[1] != logger.WarnLevel+10 {
+		t.Fatalf("unexpected hook calls %v", levels)
+	}
+}
+
+func TestNewLoggerOptions(t *testing.T) {
+	buf := &bytes.Buffer{}
+	rootLogger := logger.NewLogger(logger.WithLevel(logger.DebugLevel), logger.WithFormatter(&LineFormatter{}), logger.WithWriter(buf))
+	rootLogger.DEBUG("debug")
+	if buf.String() != "DEBUG ROOT - debug\n" {
+		t.Fatalf("unexpected output:\n%s", buf.String())
+	}
+
+	dir := t.TempDir()
+	rootLogger = logger.NewLogger(
+		logger.WithFormatter(&NopFormatter{}),
+		logger.WithRotation(&logger.RotateConfig{FilePath: dir, FileName: "stella-go.log"}),
+		logger.WithAsync(16),
+	)
+	if _, ok := rootLogger.Writer().(*logger.AsyncWriter); !ok {
+		t.Fatalf("unexpected writer %T", rootLogger.Writer())
+	}
+	rootLogger.INFO("rotated")
+	rootLogger.Close()
+	if isOpen(t, path.Join(dir, "stella-go.log")) {
+		t.Fatal("the log file is still open after Close")
+	}
+	if b, _ := os.ReadFile(path.Join(dir, "stella-go.log")); string(b) != "rotated" {
+		t.Fatalf("unexpected content %q", b)
+	}
+
+	rootLogger = logger.NewLogger(logger.WithRotation(&logger.RotateConfig{FilePath: path.Join(dir, "missing"), FileName: "stella-go.log", NoCreateDir: true}))
+	if rootLogger.Writer() != os.Stdout {
+		t.Fatalf("expected a stdout fallback, got %T", rootLogger.Writer())
+	}
+}
@@ -0,0 +1,15 @@
+module github.com/stella-go/logger/otellog
+
+go 1.25.0
+
+replace github.com/stella-go/logger => ../
+
+require (
+	github.com/stella-go/logger v0.0.0-00010101000000-000000000000
+	go.opentelemetry.io/otel/trace v1.46.0
+)
+
+require (
+	github.com/cespare/xxhash/v2 v2.3.0 // indirect
+	go.opentelemetry.io/otel v1.46.0 // indirect
+)
@@ -0,0 +1,38 @@
+// Copyright 2010-2025 the original author or authors.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+
+// 	http://www.apache.org/licenses/LICENSE-2.0
+
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package otellog extracts OpenTelemetry span ids for logger.WithTraceContext, it is
+// kept apart so that the logger itself has no dependencies.
+package otellog
+
+import (
+	"context"
+
+	"github.com/stella-go/logger"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Extract returns the trace and span ids of the span active in ctx.
+func Extract(ctx context.Context) (string, string, bool) {
+	sc := trace.SpanContextFromContext(ctx)
+	if !sc.IsValid() {
+		return "", "", false
+	}
+	return sc.TraceID().String(), sc.SpanID().String(), true
+}
+
+// Install registers Extract with logger.SetTraceExtractor.
+func Install() {
+	logger.SetTraceExtractor(Extract)
+}
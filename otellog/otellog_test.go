@@ -0,0 +1,44 @@
+// Copyright 2010-2025 the original author or authors.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+
+// 	http://www.apache.org/licenses/LICENSE-2.0
+
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package otellog_test
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/stella-go/logger"
+	"github.com/stella-go/logger/otellog"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestInstall(t *testing.T) {
+	otellog.Install()
+	defer logger.SetTraceExtractor(nil)
+
+	traceID, _ := trace.TraceIDFromHex("4bf92f3577b34da6a3ce929d0e0e4736")
+	spanID, _ := trace.SpanIDFromHex("00f067aa0ba902b7")
+	sc := trace.NewSpanContext(trace.SpanContextConfig{TraceID: traceID, SpanID: spanID})
+	ctx := trace.ContextWithSpanContext(context.Background(), sc)
+
+	buf := &bytes.Buffer{}
+	rootLogger := logger.NewRootLogger(logger.InfoLevel, &logger.PatternFormatter{Pattern: "[%x] %m"}, buf)
+	rootLogger.WithTraceContext(ctx).INFO("traced")
+	rootLogger.WithTraceContext(context.Background()).INFO("untraced")
+
+	if buf.String() != "[4bf92f3577b34da6a3ce929d0e0e4736] traced\n[] untraced\n" {
+		t.Fatalf("unexpected output:\n%s", buf.String())
+	}
+}
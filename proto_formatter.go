@@ -0,0 +1,78 @@
+// Copyright 2010-2025 the original author or authors.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+
+// 	http://www.apache.org/licenses/LICENSE-2.0
+
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logger
+
+import (
+	"encoding/binary"
+	"sort"
+)
+
+// ProtoFormatter formats entries as the Entry message of entry.proto, prefixed with its
+// length as a varint, for collectors reading length-delimited protobuf records. Field
+// values are sent as their text form.
+type ProtoFormatter struct{}
+
+func (f *ProtoFormatter) Format(e *Entry) []byte {
+	msg := make([]byte, 0, 64+len(e.Tag)+len(e.Message))
+	msg = appendProtoVarint(msg, 1, uint64(entryTime(e, nil).UnixNano()))
+	msg = appendProtoVarint(msg, 2, uint64(e.Level))
+	msg = appendProtoBytes(msg, 3, e.Tag)
+	msg = appendProtoBytes(msg, 4, e.Message)
+	if len(e.Fields) > 0 {
+		keys := make([]string, 0, len(e.Fields))
+		for k := range e.Fields {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		var field []byte
+		for _, k := range keys {
+			field = appendProtoBytes(field[:0], 1, k)
+			field = appendProtoBytes(field, 2, fieldString(e.Fields, k))
+			msg = appendProtoField(msg, 5, string(field))
+		}
+	}
+	msg = appendProtoVarint(msg, 6, e.Seq)
+	b := make([]byte, 0, binary.MaxVarintLen64+len(msg))
+	b = appendUvarint(b, uint64(len(msg)))
+	return append(b, msg...)
+}
+
+// appendProtoVarint appends a varint field, leaving out zero values as proto3 does.
+func appendProtoVarint(b []byte, field int, v uint64) []byte {
+	if v == 0 {
+		return b
+	}
+	b = appendUvarint(b, uint64(field)<<3)
+	return appendUvarint(b, v)
+}
+
+// appendProtoBytes appends a length-delimited field, leaving out empty values as proto3 does.
+func appendProtoBytes(b []byte, field int, s string) []byte {
+	if s == "" {
+		return b
+	}
+	return appendProtoField(b, field, s)
+}
+
+func appendProtoField(b []byte, field int, s string) []byte {
+	b = appendUvarint(b, uint64(field)<<3|2)
+	b = appendUvarint(b, uint64(len(s)))
+	return append(b, s...)
+}
+
+func appendUvarint(b []byte, v uint64) []byte {
+	var buf [binary.MaxVarintLen64]byte
+	return append(b, buf[:binary.PutUvarint(buf[:], v)]...)
+}
@@ -0,0 +1,123 @@
+// Copyright 2010-2025 the original author or authors.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+
+// 	http://www.apache.org/licenses/LICENSE-2.0
+
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logger_test
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"io"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/stella-go/logger"
+)
+
+type protoEntry struct {
+	timeUnixNano int64
+	level        uint64
+	tag          string
+	message      string
+	fields       map[string]string
+	seq          uint64
+}
+
+// decodeProto reads the fields of a message, calling fn with the varint value or the bytes.
+func decodeProto(t *testing.T, b []byte, fn func(field uint64, v uint64, p []byte)) {
+	for len(b) > 0 {
+		key, n := binary.Uvarint(b)
+		b = b[n:]
+		v, n := binary.Uvarint(b)
+		b = b[n:]
+		switch key & 7 {
+		case 0:
+			fn(key>>3, v, nil)
+		case 2:
+			fn(key>>3, 0, b[:v])
+			b = b[v:]
+		default:
+			t.Fatalf("unexpected wire type %d", key&7)
+		}
+	}
+}
+
+func readProtoEntry(t *testing.T, r *bufio.Reader) *protoEntry {
+	size, err := binary.ReadUvarint(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b := make([]byte, size)
+	if _, err := io.ReadFull(r, b); err != nil {
+		t.Fatal(err)
+	}
+	e := &protoEntry{fields: map[string]string{}}
+	decodeProto(t, b, func(field uint64, v uint64, p []byte) {
+		switch field {
+		case 1:
+			e.timeUnixNano = int64(v)
+		case 2:
+			e.level = v
+		case 3:
+			e.tag = string(p)
+		case 4:
+			e.message = string(p)
+		case 5:
+			var key, value string
+			decodeProto(t, p, func(field uint64, _ uint64, p []byte) {
+				if field == 1 {
+					key = string(p)
+				} else {
+					value = string(p)
+				}
+			})
+			e.fields[key] = value
+		case 6:
+			e.seq = v
+		}
+	})
+	return e
+}
+
+func TestProtoFormatter(t *testing.T) {
+	buf := &bytes.Buffer{}
+	rootLogger := logger.NewRootLogger(logger.InfoLevel, &logger.ProtoFormatter{}, buf)
+	before := time.Now()
+	rootLogger.GetLogger("db").WithFields(map[string]interface{}{"rows": 3, "empty": ""}).WARN("slow query")
+	rootLogger.INFO("")
+
+	r := bufio.NewReader(buf)
+	e := readProtoEntry(t, r)
+	if e.timeUnixNano < before.UnixNano() || e.timeUnixNano > time.Now().UnixNano() {
+		t.Fatalf("unexpected time %d", e.timeUnixNano)
+	}
+	e.timeUnixNano = 0
+	expected := &protoEntry{
+		level:   uint64(logger.WarnLevel),
+		tag:     "db",
+		message: "slow query",
+		fields:  map[string]string{"rows": "3", "empty": ""},
+		seq:     1,
+	}
+	if !reflect.DeepEqual(e, expected) {
+		t.Fatalf("unexpected entry %+v", e)
+	}
+	if e := readProtoEntry(t, r); e.message != "" || e.tag != "ROOT" || e.level != uint64(logger.InfoLevel) || e.seq != 2 {
+		t.Fatalf("unexpected entry %+v", e)
+	}
+	if r.Buffered() != 0 {
+		t.Fatalf("unexpected trailing bytes %d", r.Buffered())
+	}
+}
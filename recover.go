@@ -0,0 +1,167 @@
+// Copyright 2010-2025 the original author or authors.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+
+// 	http://www.apache.org/licenses/LICENSE-2.0
+
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logger
+
+import (
+	"errors"
+	"fmt"
+	"runtime"
+	"strconv"
+)
+
+// RecoverRePanic makes Recover panic again with the recovered value after logging it.
+var RecoverRePanic = false
+
+// DefaultRecoverMaxFrames is the number of frames of each stack logged by Recover unless
+// SetRecoverMaxFrames says otherwise.
+const DefaultRecoverMaxFrames = 32
+
+// Recover logs a recovered panic with its stack at PANIC level, it must be deferred directly:
+//
+//	defer logger.Recover(l)
+//
+// When the recovered value is an error, the stacks recorded by the errors of its chain
+// that have a Callers() []uintptr method are logged too, without the frames printed above.
+func Recover(l *Logger) {
+	if r := recover(); r != nil {
+		l.recovered(r)
+	}
+}
+
+// Recover logs a recovered panic with its stack at PANIC level, it must be deferred directly:
+//
+//	defer l.Recover()
+func (l *Logger) Recover() {
+	if r := recover(); r != nil {
+		l.recovered(r)
+	}
+}
+
+// SetRecoverMaxFrames caps each stack logged by Recover to n frames, for the root logger
+// and every logger sharing it. Consecutive identical frames, as left by a runaway
+// recursion, count once. 0 restores DefaultRecoverMaxFrames, a negative n removes the cap.
+func (l *Logger) SetRecoverMaxFrames(n int) {
+	l.internalLogger.lock.Lock()
+	defer l.internalLogger.lock.Unlock()
+	l.internalLogger.recoverMaxFrames = n
+}
+
+func (l *Logger) recovered(r interface{}) {
+	l.internalLogger.lock.Lock()
+	max := l.internalLogger.recoverMaxFrames
+	l.internalLogger.lock.Unlock()
+	if max == 0 {
+		max = DefaultRecoverMaxFrames
+	}
+	seen := make(map[frameKey]bool)
+	msg := []byte(fmt.Sprintf("panic: %v\n", r))
+	msg = appendStack(msg, callers(3), max, seen)
+	if err, ok := r.(error); ok {
+		msg = appendErrorStacks(msg, err, max, seen)
+	}
+	entry := &Entry{
+		Tag:     l.tag,
+		Level:   PanicLevel,
+		Message: string(msg),
+		Fields:  l.fields,
+	}
+	l.logEntry(entry)
+	if RecoverRePanic {
+		panic(r)
+	}
+}
+
+// callers returns the stack of the caller, skip frames up.
+func callers(skip int) []uintptr {
+	pcs := make([]uintptr, 1024)
+	return pcs[:runtime.Callers(skip+1, pcs)]
+}
+
+// appendErrorStacks appends the stacks recorded by the errors of the chain of err.
+func appendErrorStacks(b []byte, err error, max int, seen map[frameKey]bool) []byte {
+	for ; err != nil; err = errors.Unwrap(err) {
+		if st, ok := err.(interface{ Callers() []uintptr }); ok {
+			b = append(b, "error: "...)
+			b = append(b, err.Error()...)
+			b = append(b, '\n')
+			b = appendStack(b, st.Callers(), max, seen)
+		}
+	}
+	return b
+}
+
+// frameKey identifies a printed frame, frames of different stacks with the same key print
+// the same, even when their pc differ.
+type frameKey struct {
+	function string
+	file     string
+	line     int
+}
+
+// appendStack formats the frames of pcs, collapsing consecutive identical frames and those
+// already in seen, printing at most max frames, max <= 0 meaning no limit. The printed
+// frames are added to seen.
+func appendStack(b []byte, pcs []uintptr, max int, seen map[frameKey]bool) []byte {
+	frames := runtime.CallersFrames(pcs)
+	var last runtime.Frame
+	var printed []frameKey
+	repeated, above, omitted := 0, 0, 0
+	flush := func() {
+		if repeated > 0 {
+			b = append(b, "\t... repeated "...)
+			b = strconv.AppendInt(b, int64(repeated), 10)
+			b = append(b, " times\n"...)
+			repeated = 0
+		}
+	}
+	for {
+		frame, more := frames.Next()
+		if len(printed) > 0 && frame.Function == last.Function && frame.File == last.File && frame.Line == last.Line {
+			repeated++
+		} else if seen[frameKey{frame.Function, frame.File, frame.Line}] {
+			above++
+		} else if max > 0 && len(printed) >= max {
+			omitted++
+		} else {
+			flush()
+			b = append(b, frame.Function...)
+			b = append(b, "\n\t"...)
+			b = append(b, frame.File...)
+			b = append(b, ':')
+			b = strconv.AppendInt(b, int64(frame.Line), 10)
+			b = append(b, '\n')
+			printed = append(printed, frameKey{frame.Function, frame.File, frame.Line})
+			last = frame
+		}
+		if !more {
+			break
+		}
+	}
+	flush()
+	if above > 0 {
+		b = append(b, "... "...)
+		b = strconv.AppendInt(b, int64(above), 10)
+		b = append(b, " frames printed above\n"...)
+	}
+	if omitted > 0 {
+		b = append(b, "... "...)
+		b = strconv.AppendInt(b, int64(omitted), 10)
+		b = append(b, " more frames\n"...)
+	}
+	for _, key := range printed {
+		seen[key] = true
+	}
+	return b
+}
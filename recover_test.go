@@ -0,0 +1,163 @@
+// Copyright 2010-2025 the original author or authors.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+
+// 	http://www.apache.org/licenses/LICENSE-2.0
+
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logger_test
+
+import (
+	"bytes"
+	"fmt"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/stella-go/logger"
+)
+
+func TestRecover(t *testing.T) {
+	buf := &bytes.Buffer{}
+	rootLogger := logger.NewRootLogger(logger.InfoLevel, &LineFormatter{}, buf)
+	func() {
+		defer logger.Recover(rootLogger)
+		panic("boom")
+	}()
+	func() {
+		defer rootLogger.GetLogger("Worker").Recover()
+		panic("bang")
+	}()
+	output := buf.String()
+	if !strings.HasPrefix(output, "PANIC ROOT - panic: boom\n") || !strings.Contains(output, "PANIC Worker - panic: bang\n") {
+		t.Fatalf("unexpected output:\n%s", output)
+	}
+	if !strings.Contains(output, "recover_test.go") {
+		t.Fatalf("stack trace missing:\n%s", output)
+	}
+}
+
+func TestRecoverRePanic(t *testing.T) {
+	buf := &bytes.Buffer{}
+	rootLogger := logger.NewRootLogger(logger.InfoLevel, &LineFormatter{}, buf)
+	logger.RecoverRePanic = true
+	defer func() {
+		logger.RecoverRePanic = false
+		if r := recover(); r != "boom" {
+			t.Fatalf("unexpected recovered value %v", r)
+		}
+		if !strings.HasPrefix(buf.String(), "PANIC ROOT - panic: boom\n") {
+			t.Fatalf("unexpected output:\n%s", buf.String())
+		}
+	}()
+	func() {
+		defer rootLogger.Recover()
+		panic("boom")
+	}()
+}
+
+func recurse(n int) {
+	if n == 0 {
+		panic("deep")
+	}
+	recurse(n - 1)
+}
+
+func TestRecoverStackDepth(t *testing.T) {
+	buf := &bytes.Buffer{}
+	rootLogger := logger.NewRootLogger(logger.InfoLevel, &LineFormatter{}, buf)
+	func() {
+		defer rootLogger.Recover()
+		recurse(200)
+	}()
+	output := buf.String()
+	if !strings.Contains(output, "logger_test.recurse\n") || !strings.Contains(output, "\t... repeated 199 times\n") {
+		t.Fatalf("recursion not collapsed:\n%s", output)
+	}
+	if strings.Count(output, "logger_test.recurse\n") != 2 {
+		t.Fatalf("unexpected recurse frames:\n%s", output)
+	}
+
+	buf.Reset()
+	rootLogger.SetRecoverMaxFrames(2)
+	func() {
+		defer rootLogger.Recover()
+		recurse(3)
+	}()
+	if lines := strings.Count(buf.String(), "\n\t"); lines != 2 || !strings.Contains(buf.String(), " more frames\n") {
+		t.Fatalf("stack not capped:\n%s", buf.String())
+	}
+}
+
+// stackError records the stack where it was created, like the errors of several error packages.
+type stackError struct {
+	msg   string
+	cause error
+	pcs   []uintptr
+}
+
+func newStackError(msg string, cause error) error {
+	pcs := make([]uintptr, 64)
+	return &stackError{msg: msg, cause: cause, pcs: pcs[:runtime.Callers(2, pcs)]}
+}
+
+func (e *stackError) Error() string {
+	if e.cause == nil {
+		return e.msg
+	}
+	return e.msg + ": " + e.cause.Error()
+}
+
+func (e *stackError) Unwrap() error {
+	return e.cause
+}
+
+func (e *stackError) Callers() []uintptr {
+	return e.pcs
+}
+
+func queryRow() error {
+	return newStackError("connection reset", nil)
+}
+
+func loadUser() error {
+	return newStackError("load user", queryRow())
+}
+
+func handleRequest() error {
+	return newStackError("handle request", fmt.Errorf("wrapped: %w", loadUser()))
+}
+
+func TestRecoverWrappedError(t *testing.T) {
+	buf := &bytes.Buffer{}
+	rootLogger := logger.NewRootLogger(logger.InfoLevel, &LineFormatter{}, buf)
+	func() {
+		defer rootLogger.Recover()
+		panic(handleRequest())
+	}()
+	output := buf.String()
+	if !strings.HasPrefix(output, "PANIC ROOT - panic: handle request: wrapped: load user: connection reset\n") {
+		t.Fatalf("unexpected output:\n%s", output)
+	}
+	for _, header := range []string{"error: handle request: ", "error: load user: ", "error: connection reset\n"} {
+		if strings.Count(output, header) != 1 {
+			t.Fatalf("missing stack of %q:\n%s", header, output)
+		}
+	}
+	// each function is printed once, the frames shared with the outer layers are collapsed
+	for _, fn := range []string{"logger_test.handleRequest\n", "logger_test.loadUser\n", "logger_test.queryRow\n", "logger_test.TestRecoverWrappedError.func1\n"} {
+		if n := strings.Count(output, fn); n != 1 {
+			t.Fatalf("%q printed %d times:\n%s", fn, n, output)
+		}
+	}
+	if strings.Count(output, " frames printed above\n") != 3 {
+		t.Fatalf("shared frames not collapsed:\n%s", output)
+	}
+}
@@ -16,11 +16,14 @@ package logger
 
 import (
 	"fmt"
+	"io"
+	"math"
 	"os"
 	"path"
 	"sort"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 )
 
@@ -32,88 +35,335 @@ const (
 )
 
 type RotateConfig struct {
-	Enable      bool
-	Daily       bool
+	Enable bool
+	Daily  bool
+	// MaxFiles is the number of files kept, the active one included: 1 keeps no archive so
+	// rotating discards the old lines and OnRotate is not called, 0 keeps every archive.
 	MaxFiles    int
 	MaxFileSize int64
 	FilePath    string
 	FileName    string
+	NoCreateDir bool // fail instead of creating FilePath when it is missing
+	// Truncate empties the file when the writer is created instead of appending to it,
+	// which is meant for single-run tools: rotation still renames the truncated file, so
+	// archives only hold the current run. Later reopens always append.
+	Truncate bool
+	// OnRotate is called with the active file path and the archive path after each
+	// successful rotation. It runs outside the writer lock, so it may run concurrently
+	// with writes to the new file.
+	OnRotate func(oldPath, newPath string)
+	// SyncEveryWrite calls fsync after each write so a crash loses no written line. Every
+	// write then waits for the disk, which can cut throughput by orders of magnitude, so
+	// keep it for audit logs. It has no effect on stdout and stderr.
+	SyncEveryWrite bool
+	// SyncLevel calls fsync after the entries at or above this level, such as ErrorLevel,
+	// while lower ones are left to the page cache. It needs the writer to receive entries,
+	// which loggers do through WriteEntry. The zero value TraceLevel disables it.
+	SyncLevel Level
+	// DatedActiveFile writes to FileName with the date inserted before its extension,
+	// such as app-20250601.log, and opens a new file at the day boundary instead of
+	// renaming, even when Enable is false. Size rotation and MaxFiles still apply when
+	// enabled, MaxFiles counting every dated file.
+	DatedActiveFile bool
+	// Location is the time zone of the daily boundary and the archive date, nil means local.
+	// Keep it in line with the formatter's zone to avoid confusing archive dates.
+	Location *time.Location
+	// WatchInode reopens the file when its path no longer leads to the open file, as after
+	// an external logrotate renamed it without signaling us. The path is checked at most
+	// once per second. A copytruncate keeps the same file, which appending handles already.
+	WatchInode bool
 }
 
+// inodeCheckInterval throttles the WatchInode check.
+var inodeCheckInterval = time.Second
+
 type RotateWriter struct {
-	config *RotateConfig
-	dest   *os.File
+	stats   stats
+	config  *RotateConfig
+	dest    file
+	fs      fileSystem
+	name    string // name of the active file, FileName or its dated form
+	checked time.Time
+	lock    sync.Mutex
 }
 
 func (w *RotateWriter) Write(p []byte) (int, error) {
-	w.tryRotate()
-	return w.dest.Write(p)
+	return w.write(p, TraceLevel)
+}
+
+// WriteEntry writes p like Write and syncs it when e is at or above SyncLevel.
+func (w *RotateWriter) WriteEntry(e *Entry, p []byte) (int, error) {
+	return w.write(p, e.Level)
+}
+
+func (w *RotateWriter) write(p []byte, level Level) (int, error) {
+	w.lock.Lock()
+	callback := w.config.OnRotate
+	archive := w.tryRotate()
+	active := w.activePath()
+	n, err := w.dest.Write(p)
+	sync := w.config.SyncEveryWrite || w.config.SyncLevel > TraceLevel && level >= w.config.SyncLevel
+	if err == nil && sync && w.dest != os.Stdout && w.dest != os.Stderr {
+		err = w.dest.Sync()
+	}
+	w.lock.Unlock()
+	w.stats.written(n, err)
+	onRotate(callback, active, archive)
+	return n, err
 }
 
-func (w *RotateWriter) tryRotate() {
+func (w *RotateWriter) Stats() Stats {
+	return w.stats.snapshot()
+}
+
+// Close closes the file, stdout and stderr are left open.
+func (w *RotateWriter) Close() error {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	if w.dest == os.Stdout || w.dest == os.Stderr {
+		return nil
+	}
+	return w.dest.Close()
+}
+
+// Rotate archives the current file immediately regardless of the configured thresholds.
+func (w *RotateWriter) Rotate() error {
+	w.lock.Lock()
+	callback := w.config.OnRotate
+	archive, err := w.rotate()
+	active := w.activePath()
+	w.lock.Unlock()
+	onRotate(callback, active, archive)
+	return err
+}
+
+func (w *RotateWriter) tryRotate() string {
+	if w.config.WatchInode && w.name != "" {
+		w.checkInode()
+	}
+	archive := ""
+	if w.config.DatedActiveFile && w.name != "" {
+		if name := activeName(w.config); name != w.name {
+			if oldPath, err := w.switchFile(name); err != nil {
+				print("RotateWriter", "ERROR", "%v", err)
+			} else {
+				archive = oldPath
+			}
+		}
+	}
 	if !w.config.Enable {
-		return
+		return archive
 	}
-	if w.config.Daily {
-		if fi, err := w.dest.Stat(); err == nil && fi.ModTime().Local().Format("20060102") != time.Now().Local().Format("20060102") {
-			w.rotate()
+	if w.config.Daily && !w.config.DatedActiveFile {
+		if fi, err := w.dest.Stat(); err == nil && fi.ModTime().In(w.location()).Format("20060102") != time.Now().In(w.location()).Format("20060102") {
+			if newPath, err := w.rotate(); err != nil {
+				print("RotateWriter", "ERROR", "%v", err)
+			} else {
+				archive = newPath
+			}
 		}
 	}
 	if w.config.MaxFileSize > 0 {
 		if fi, err := w.dest.Stat(); err == nil && fi.Size() > w.config.MaxFileSize {
-			w.rotate()
+			if newPath, err := w.rotate(); err != nil {
+				print("RotateWriter", "ERROR", "%v", err)
+			} else {
+				archive = newPath
+			}
 		}
 	}
+	return archive
 }
 
-type sfis []os.FileInfo
+// checkInode reopens the active path when it leads to another file than the open one.
+func (w *RotateWriter) checkInode() {
+	now := time.Now()
+	if now.Sub(w.checked) < inodeCheckInterval {
+		return
+	}
+	w.checked = now
+	fi, err := w.dest.Stat()
+	if err != nil {
+		return
+	}
+	if pfi, err := w.fs.Stat(w.activePath()); err == nil && os.SameFile(fi, pfi) {
+		return
+	}
+	fo, err := openFile(w.fs, w.config, w.name, false)
+	if err != nil {
+		print("RotateWriter", "ERROR", "Reopen file error: %v", err)
+		return
+	}
+	w.dest.Close()
+	w.dest = fo
+}
 
-func (p sfis) Len() int {
-	return len(p)
+func (w *RotateWriter) location() *time.Location {
+	return w.config.location()
 }
-func (p sfis) Less(i, j int) bool {
-	return p[i].ModTime().After(p[j].ModTime()) // new -> ... -> old
+
+func (c *RotateConfig) location() *time.Location {
+	if c.Location == nil {
+		return time.Local
+	}
+	return c.Location
 }
-func (p sfis) Swap(i, j int) {
-	p[i], p[j] = p[j], p[i]
+
+func (w *RotateWriter) activePath() string {
+	return path.Join(w.config.FilePath, w.name)
 }
 
-func (w *RotateWriter) rotate() {
-	if w.dest == os.Stdout || w.dest == os.Stderr {
-		return
+// onRotate runs callback, which is read under the lock since SetConfig may replace the config.
+func onRotate(callback func(oldPath, newPath string), active string, archive string) {
+	if archive != "" && callback != nil {
+		callback(active, archive)
 	}
-	entries, err := os.ReadDir(w.config.FilePath)
+}
+
+// switchFile moves to the dated file name and returns the path of the previous one.
+func (w *RotateWriter) switchFile(name string) (string, error) {
+	fo, err := openFile(w.fs, w.config, name, false)
 	if err != nil {
-		print("ERROR", "Get file list error: %v", err)
-		return
+		return "", fmt.Errorf("Open file error: %w", err)
+	}
+	oldPath := w.activePath()
+	w.dest.Close()
+	w.dest = fo
+	w.name = name
+	w.stats.rotated()
+	if w.config.MaxFiles > 0 {
+		names, err := w.series()
+		if err != nil {
+			print("RotateWriter", "ERROR", "%v", err)
+		} else if len(names) > w.config.MaxFiles {
+			for _, name := range names[w.config.MaxFiles:] {
+				if err := w.fs.Remove(path.Join(w.config.FilePath, name)); err != nil {
+					print("RotateWriter", "ERROR", "Remove file error: %v", err)
+				}
+			}
+		}
+	}
+	return oldPath, nil
+}
+
+// series returns the names of the active file and its archives, newest first.
+func (w *RotateWriter) series() ([]string, error) {
+	fis, err := w.seriesInfo()
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(fis))
+	for _, fileInfo := range fis {
+		names = append(names, fileInfo.Name())
+	}
+	return names, nil
+}
+
+// seriesInfo returns the active file and its archives, newest first.
+func (w *RotateWriter) seriesInfo() ([]os.FileInfo, error) {
+	entries, err := w.fs.ReadDir(w.config.FilePath)
+	if err != nil {
+		return nil, fmt.Errorf("Get file list error: %w", err)
 	}
 	fis := make([]os.FileInfo, 0, len(entries))
 	for _, entry := range entries {
+		if !w.inSeries(entry.Name()) {
+			continue
+		}
 		info, err := entry.Info()
 		if err != nil {
-			print("ERROR", "Get file info error: %v", err)
-			return
+			return nil, fmt.Errorf("Get file info error: %w", err)
 		}
 		fis = append(fis, info)
 	}
-	sfis := sfis(fis)
-	sort.Sort(sfis)
-	series := make([]os.FileInfo, 0)
-	for _, fileInfo := range sfis {
-		if strings.HasPrefix(fileInfo.Name(), w.config.FileName) {
-			series = append(series, fileInfo)
+	sort.Sort(sfis(fis))
+	return fis, nil
+}
+
+// ArchiveInfo describes an archived log file.
+type ArchiveInfo struct {
+	Name       string
+	Size       int64
+	ModTime    time.Time
+	Compressed bool // the name ends with .gz
+}
+
+// Archives lists the archives of the active file, newest first, matched as rotation
+// matches them when pruning MaxFiles.
+func (w *RotateWriter) Archives() ([]ArchiveInfo, error) {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	if w.name == "" {
+		return nil, nil
+	}
+	fis, err := w.seriesInfo()
+	if err != nil {
+		return nil, err
+	}
+	archives := make([]ArchiveInfo, 0, len(fis))
+	for _, fi := range fis {
+		if fi.Name() == w.name || fi.IsDir() {
+			continue
 		}
+		archives = append(archives, ArchiveInfo{
+			Name:       fi.Name(),
+			Size:       fi.Size(),
+			ModTime:    fi.ModTime(),
+			Compressed: strings.HasSuffix(fi.Name(), ".gz"),
+		})
+	}
+	return archives, nil
+}
+
+// inSeries reports whether name is FileName or one of its archives, dated as
+// app-20250601.log* when DatedActiveFile is set.
+func (w *RotateWriter) inSeries(name string) bool {
+	if !w.config.DatedActiveFile {
+		return strings.HasPrefix(name, w.config.FileName)
 	}
-	names := make([]string, len(series))
-	for i, s := range series {
-		names[i] = s.Name()
+	ext := path.Ext(w.config.FileName)
+	prefix := strings.TrimSuffix(w.config.FileName, ext) + "-"
+	if !strings.HasPrefix(name, prefix) {
+		return false
+	}
+	rest := name[len(prefix):]
+	if len(rest) < 8 || !strings.HasPrefix(rest[8:], ext) {
+		return false
+	}
+	for _, c := range rest[:8] {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+type sfis []os.FileInfo
+
+func (p sfis) Len() int {
+	return len(p)
+}
+func (p sfis) Less(i, j int) bool {
+	return p[i].ModTime().After(p[j].ModTime()) // new -> ... -> old
+}
+func (p sfis) Swap(i, j int) {
+	p[i], p[j] = p[j], p[i]
+}
+
+func (w *RotateWriter) rotate() (string, error) {
+	if w.name == "" {
+		return "", nil
+	}
+	names, err := w.series()
+	if err != nil {
+		return "", err
 	}
 	fileInfo, err := w.dest.Stat()
 	if err != nil {
-		print("ERROR", "Get file stat error: %v", err)
+		return "", fmt.Errorf("Get file stat error: %w", err)
 	}
-	date := fileInfo.ModTime().Local().Format("20060102")
-	newName := fmt.Sprintf("%s.%s", w.config.FileName, date)
+	date := fileInfo.ModTime().In(w.location()).Format("20060102")
+	newName := fmt.Sprintf("%s.%s", w.name, date)
 	index := 1
 	for _, name := range names {
 		if strings.HasPrefix(name, newName) {
@@ -121,7 +371,7 @@ func (w *RotateWriter) rotate() {
 			if len(suffix) != 0 {
 				i, err := strconv.Atoi(suffix[1:])
 				if err != nil {
-					print("ERROR", "Parse file index error: %v", err)
+					print("RotateWriter", "ERROR", "Parse file index error: %v", err)
 				}
 				if i >= index {
 					index = i + 1
@@ -130,68 +380,175 @@ func (w *RotateWriter) rotate() {
 		}
 	}
 	newName = fmt.Sprintf("%s.%d", newName, index)
-	oldPath := path.Join(w.config.FilePath, w.config.FileName)
+	oldPath := w.activePath()
 	newPath := path.Join(w.config.FilePath, newName)
-	err = os.Rename(oldPath, newPath)
+	err = w.fs.Rename(oldPath, newPath)
 	if err != nil {
-		print("ERROR", "Rename file error: %v", err)
-		return
+		return "", fmt.Errorf("Rename file error: %w", err)
 	}
-	fo, err := os.OpenFile(oldPath, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	fo, err := w.fs.OpenFile(oldPath, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
 	if err != nil {
-		print("ERROR", "Open file error: %v", err)
-		return
+		return "", fmt.Errorf("Open file error: %w", err)
 	}
+	w.dest.Close()
 	w.dest = fo
+	w.stats.rotated()
 
-	if len(names) > w.config.MaxFiles-1 {
-		for _, name := range names[w.config.MaxFiles-1:] {
+	if w.config.MaxFiles <= 0 {
+		return newPath, nil
+	}
+	archives := []string{newName}
+	for _, name := range names {
+		if name != w.name {
+			archives = append(archives, name)
+		}
+	}
+	if keep := w.config.MaxFiles - 1; len(archives) > keep {
+		for _, name := range archives[keep:] {
 			p := path.Join(w.config.FilePath, name)
-			err := os.Remove(p)
+			err := w.fs.Remove(p)
 			if err != nil {
-				print("ERROR", "Remove file error: %v", err)
+				print("RotateWriter", "ERROR", "Remove file error: %v", err)
 			}
 		}
+		if keep == 0 {
+			return "", nil
+		}
+	}
+	return newPath, nil
+}
+
+func (c *RotateConfig) validate() error {
+	if c.MaxFiles < 0 || c.MaxFileSize < 0 {
+		return fmt.Errorf("invalid rotate config: MaxFiles %d, MaxFileSize %d", c.MaxFiles, c.MaxFileSize)
 	}
+	return nil
 }
 
 func NewConfigRotateWriter(config *RotateConfig) (*RotateWriter, error) {
+	return newRotateWriter(config, osFileSystem{})
+}
+
+func newRotateWriter(config *RotateConfig, fs fileSystem) (*RotateWriter, error) {
+	if err := config.validate(); err != nil {
+		return nil, err
+	}
+	dest, name, err := openDest(fs, config, config.Truncate)
+	if err != nil {
+		return nil, err
+	}
+	return &RotateWriter{
+		config: config,
+		dest:   dest,
+		fs:     fs,
+		name:   name,
+	}, nil
+}
+
+// NewFileRotateWriter adopts a file opened by the caller, such as an inherited or socket
+// activated descriptor. With an empty FileName the file is never rotated since it has no
+// known path. When FilePath and FileName name the file, rotation works as usual and
+// reopens the file by path afterwards, DatedActiveFile is not supported and also disables
+// rotation. SyncEveryWrite fails on pipes and sockets. A nil config means no rotation.
+func NewFileRotateWriter(f *os.File, config *RotateConfig) *RotateWriter {
+	if config == nil {
+		config = &RotateConfig{}
+	}
+	name := config.FileName
+	if config.DatedActiveFile {
+		name = ""
+	}
+	return &RotateWriter{
+		config: config,
+		dest:   f,
+		fs:     osFileSystem{},
+		name:   name,
+	}
+}
+
+// openDest opens the active file of config and returns it with its name, which is empty for stdout and stderr.
+func openDest(fs fileSystem, config *RotateConfig, truncate bool) (file, string, error) {
 	switch config.FileName {
 	case "", "stdout":
-		return &RotateWriter{
-			config: config,
-			dest:   os.Stdout,
-		}, nil
+		return os.Stdout, "", nil
 	case "stderr":
-		return &RotateWriter{
-			config: config,
-			dest:   os.Stderr,
-		}, nil
+		return os.Stderr, "", nil
 	default:
 
 	}
-	exist, err := isExists(config.FilePath)
+	name := activeName(config)
+	fo, err := openFile(fs, config, name, truncate)
+	if err != nil {
+		return nil, "", err
+	}
+	return fo, name, nil
+}
+
+func activeName(config *RotateConfig) string {
+	if !config.DatedActiveFile {
+		return config.FileName
+	}
+	ext := path.Ext(config.FileName)
+	date := time.Now().In(config.location()).Format("20060102")
+	return strings.TrimSuffix(config.FileName, ext) + "-" + date + ext
+}
+
+func openFile(fs fileSystem, config *RotateConfig, name string, truncate bool) (file, error) {
+	exist, err := isExists(fs, config.FilePath)
 	if err != nil {
 		return nil, err
 	}
 	if !exist {
-		err := os.MkdirAll(config.FilePath, 0755)
+		if config.NoCreateDir {
+			return nil, fmt.Errorf("log directory %s does not exist", config.FilePath)
+		}
+		err := fs.MkdirAll(config.FilePath, 0755)
 		if err != nil {
 			return nil, err
 		}
 	}
-	fo, err := os.OpenFile(path.Join(config.FilePath, config.FileName), os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
-	if err != nil {
-		return nil, err
+	flag := os.O_CREATE | os.O_WRONLY
+	if truncate {
+		flag |= os.O_TRUNC
+	} else {
+		flag |= os.O_APPEND
 	}
-	return &RotateWriter{
-		config: config,
-		dest:   fo,
-	}, nil
+	return fs.OpenFile(path.Join(config.FilePath, name), flag, 0644)
+}
+
+// SetConfig replaces the configuration at runtime, for example to change MaxFileSize.
+// The file is reopened when FilePath or FileName changed, the current one is kept
+// when the new one cannot be opened.
+func (w *RotateWriter) SetConfig(config *RotateConfig) error {
+	if config == nil {
+		return fmt.Errorf("nil rotate config")
+	}
+	if err := config.validate(); err != nil {
+		return err
+	}
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	if config.FilePath != w.config.FilePath || config.FileName != w.config.FileName || config.DatedActiveFile != w.config.DatedActiveFile {
+		dest, name, err := openDest(w.fs, config, false)
+		if err != nil {
+			return err
+		}
+		if w.dest != os.Stdout && w.dest != os.Stderr {
+			w.dest.Close()
+		}
+		w.dest = dest
+		w.name = name
+	}
+	w.config = config
+	return nil
 }
 
 func NewRotateWriter(filePath string, fileName string) (*RotateWriter, error) {
-	config := &RotateConfig{
+	return NewConfigRotateWriter(defaultRotateConfig(filePath, fileName))
+}
+
+func defaultRotateConfig(filePath string, fileName string) *RotateConfig {
+	return &RotateConfig{
 		Enable:      true,
 		Daily:       true,
 		MaxFiles:    31,
@@ -199,11 +556,58 @@ func NewRotateWriter(filePath string, fileName string) (*RotateWriter, error) {
 		FilePath:    strings.TrimSpace(filePath),
 		FileName:    strings.TrimSpace(fileName),
 	}
-	return NewConfigRotateWriter(config)
 }
 
-func isExists(path string) (bool, error) {
-	_, err := os.Stat(path)
+// ParseSize parses sizes such as "512k", "200M" or "1.5GB", the suffix is case-insensitive
+// and a number without suffix is in bytes.
+func ParseSize(s string) (int64, error) {
+	str := strings.ToUpper(strings.TrimSpace(s))
+	str = strings.TrimSuffix(str, "B")
+	unit := int64(FileSizeB)
+	switch {
+	case strings.HasSuffix(str, "K"):
+		unit = FileSizeK
+	case strings.HasSuffix(str, "M"):
+		unit = FileSizeM
+	case strings.HasSuffix(str, "G"):
+		unit = FileSizeG
+	}
+	if unit != FileSizeB {
+		str = str[:len(str)-1]
+	}
+	n, err := strconv.ParseFloat(strings.TrimSpace(str), 64)
+	if err != nil || n < 0 || math.IsInf(n, 0) || math.IsNaN(n) {
+		return 0, fmt.Errorf("invalid size %q", s)
+	}
+	size := n * float64(unit)
+	// float64(math.MaxInt64) rounds up to 2^63, which is already out of range
+	if size >= float64(math.MaxInt64) {
+		return 0, fmt.Errorf("size too large %q", s)
+	}
+	return int64(size), nil
+}
+
+// FormatSize formats n bytes with the largest fitting unit, such as "1.5G" or "512K".
+func FormatSize(n int64) string {
+	units := []struct {
+		size   int64
+		suffix string
+	}{
+		{FileSizeG, "G"},
+		{FileSizeM, "M"},
+		{FileSizeK, "K"},
+	}
+	for _, u := range units {
+		if n >= u.size || -n >= u.size {
+			v := math.Round(float64(n)/float64(u.size)*100) / 100
+			return strconv.FormatFloat(v, 'f', -1, 64) + u.suffix
+		}
+	}
+	return strconv.FormatInt(n, 10) + "B"
+}
+
+func isExists(fs fileSystem, path string) (bool, error) {
+	_, err := fs.Stat(path)
 	if err == nil {
 		return true, nil
 	}
@@ -213,9 +617,14 @@ func isExists(path string) (bool, error) {
 	return false, err
 }
 
-func print(tag string, format string, a ...interface{}) (int, error) {
+func print(name string, tag string, format string, a ...interface{}) (int, error) {
+	return fprint(os.Stdout, name, tag, format, a...)
+}
+
+// fprint is print to w, the reentrant write path uses it to report on stderr.
+func fprint(w io.Writer, name string, tag string, format string, a ...interface{}) (int, error) {
 	msg := fmt.Sprintf(format, a...)
 	now := time.Now().Local()
 	datetime := now.Format("2006/01/02 15:04:05")
-	return fmt.Printf("%s [%s] RotateWriter - %s\n", datetime, tag, msg)
+	return fmt.Fprintf(w, "%s [%s] %s - %s\n", datetime, tag, name, msg)
 }
@@ -15,7 +15,13 @@
 package logger_test
 
 import (
+	"errors"
+	"io"
+	"os"
+	"path"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/stella-go/logger"
 )
@@ -59,3 +65,507 @@ func TestNewConfigRotateWriter3(t *testing.T) {
 		writer.Write([]byte("1234567890"))
 	}
 }
+
+func TestNewConfigRotateWriterWithoutCreateDir(t *testing.T) {
+	dir := path.Join(t.TempDir(), "missing")
+	config := &logger.RotateConfig{
+		FilePath:    dir,
+		FileName:    "stella-go.log",
+		NoCreateDir: true,
+	}
+	if _, err := logger.NewConfigRotateWriter(config); err == nil {
+		t.Fatal("expected an error for a missing directory")
+	}
+	if _, err := os.Stat(dir); !os.IsNotExist(err) {
+		t.Fatal("directory should not be created")
+	}
+}
+
+func TestNewConfigRotateWriterTruncate(t *testing.T) {
+	dir := t.TempDir()
+	for _, truncate := range []bool{false, true} {
+		config := &logger.RotateConfig{
+			FilePath: dir,
+			FileName: "stella-go.log",
+			Truncate: truncate,
+		}
+		for i := 0; i < 2; i++ {
+			writer, err := logger.NewConfigRotateWriter(config)
+			if err != nil {
+				t.Fatal(err)
+			}
+			writer.Write([]byte("abc"))
+		}
+		b, _ := os.ReadFile(path.Join(dir, "stella-go.log"))
+		if !truncate && string(b) != "abcabc" || truncate && string(b) != "abc" {
+			t.Fatalf("unexpected content %q with truncate=%v", b, truncate)
+		}
+	}
+}
+
+func TestRotate(t *testing.T) {
+	dir := t.TempDir()
+	writer, err := logger.NewConfigRotateWriter(&logger.RotateConfig{
+		MaxFiles: 5,
+		FilePath: dir,
+		FileName: "stella-go.log",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	writer.Write([]byte("abc"))
+	if err := writer.Rotate(); err != nil {
+		t.Fatal(err)
+	}
+	writer.Write([]byte("def"))
+
+	archive := path.Join(dir, "stella-go.log."+time.Now().Format("20060102")+".1")
+	if b, _ := os.ReadFile(archive); string(b) != "abc" {
+		t.Fatalf("unexpected archive content %q", b)
+	}
+	if b, _ := os.ReadFile(path.Join(dir, "stella-go.log")); string(b) != "def" {
+		t.Fatalf("unexpected active content %q", b)
+	}
+}
+
+func TestArchives(t *testing.T) {
+	dir := t.TempDir()
+	writer, err := logger.NewConfigRotateWriter(&logger.RotateConfig{
+		MaxFiles: 5,
+		FilePath: dir,
+		FileName: "stella-go.log",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer writer.Close()
+	prefix := "stella-go.log." + time.Now().Format("20060102")
+	writer.Write([]byte("abc"))
+	writer.Rotate()
+	old := time.Now().Add(-time.Hour)
+	os.Chtimes(path.Join(dir, prefix+".1"), old, old)
+	writer.Write([]byte("defg"))
+	writer.Rotate()
+	writer.Write([]byte("h"))
+	os.WriteFile(path.Join(dir, "other.log"), []byte("x"), 0644)
+
+	archives, err := writer.Archives()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(archives) != 2 {
+		t.Fatalf("unexpected archives %+v", archives)
+	}
+	if a := archives[0]; a.Name != prefix+".2" || a.Size != 4 || a.Compressed {
+		t.Fatalf("unexpected archive %+v", a)
+	}
+	if a := archives[1]; a.Name != prefix+".1" || a.Size != 3 || !a.ModTime.Equal(old) {
+		t.Fatalf("unexpected archive %+v", a)
+	}
+}
+
+func TestOnRotate(t *testing.T) {
+	dir := t.TempDir()
+	var oldPath, newPath string
+	writer, err := logger.NewConfigRotateWriter(&logger.RotateConfig{
+		Enable:      true,
+		MaxFiles:    5,
+		MaxFileSize: 5 * logger.FileSizeB,
+		FilePath:    dir,
+		FileName:    "stella-go.log",
+		OnRotate: func(o, n string) {
+			oldPath, newPath = o, n
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	writer.Write([]byte("1234567890"))
+	writer.Write([]byte("1234567890"))
+
+	if oldPath != path.Join(dir, "stella-go.log") {
+		t.Fatalf("unexpected old path %s", oldPath)
+	}
+	if newPath != path.Join(dir, "stella-go.log."+time.Now().Format("20060102")+".1") {
+		t.Fatalf("unexpected new path %s", newPath)
+	}
+}
+
+func TestSyncEveryWrite(t *testing.T) {
+	dir := t.TempDir()
+	writer, err := logger.NewConfigRotateWriter(&logger.RotateConfig{
+		FilePath:       dir,
+		FileName:       "stella-go.log",
+		SyncEveryWrite: true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := writer.Write([]byte("abc")); err != nil {
+		t.Fatal(err)
+	}
+	if b, _ := os.ReadFile(path.Join(dir, "stella-go.log")); string(b) != "abc" {
+		t.Fatalf("unexpected content %q", b)
+	}
+	if stats := writer.Stats(); stats.Errors != 0 {
+		t.Fatalf("unexpected errors %d", stats.Errors)
+	}
+}
+
+func TestWatchInode(t *testing.T) {
+	defer logger.SetInodeCheckInterval(0)()
+	dir := t.TempDir()
+	writer, err := logger.NewConfigRotateWriter(&logger.RotateConfig{
+		FilePath:   dir,
+		FileName:   "stella-go.log",
+		WatchInode: true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer writer.Close()
+	writer.Write([]byte("abc"))
+	if err := os.Rename(path.Join(dir, "stella-go.log"), path.Join(dir, "stella-go.log.1")); err != nil {
+		t.Fatal(err)
+	}
+	writer.Write([]byte("def"))
+	if b, _ := os.ReadFile(path.Join(dir, "stella-go.log.1")); string(b) != "abc" {
+		t.Fatalf("unexpected renamed content %q", b)
+	}
+	if b, _ := os.ReadFile(path.Join(dir, "stella-go.log")); string(b) != "def" {
+		t.Fatalf("unexpected reopened content %q", b)
+	}
+}
+
+type SyncCountingFS struct {
+	logger.OSFileSystem
+	syncs int
+}
+
+func (fs *SyncCountingFS) OpenFile(name string, flag int, perm os.FileMode) (logger.File, error) {
+	f, err := fs.OSFileSystem.OpenFile(name, flag, perm)
+	if err != nil {
+		return nil, err
+	}
+	return &syncCountingFile{File: f, fs: fs}, nil
+}
+
+type syncCountingFile struct {
+	logger.File
+	fs *SyncCountingFS
+}
+
+func (f *syncCountingFile) Sync() error {
+	f.fs.syncs++
+	return f.File.Sync()
+}
+
+func TestSyncLevel(t *testing.T) {
+	fs := &SyncCountingFS{}
+	writer, err := logger.NewConfigRotateWriterFS(&logger.RotateConfig{
+		FilePath:  t.TempDir(),
+		FileName:  "stella-go.log",
+		SyncLevel: logger.ErrorLevel,
+	}, fs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer writer.Close()
+	l := logger.NewRootLogger(logger.InfoLevel, &NopFormatter{}, writer)
+	l.INFO("routine")
+	l.WARN("routine")
+	writer.Write([]byte("raw"))
+	if fs.syncs != 0 {
+		t.Fatalf("unexpected syncs %d", fs.syncs)
+	}
+	l.ERROR("critical")
+	if fs.syncs != 1 {
+		t.Fatalf("unexpected syncs %d", fs.syncs)
+	}
+}
+
+func TestSetConfig(t *testing.T) {
+	dir := t.TempDir()
+	config := &logger.RotateConfig{
+		Enable:   true,
+		MaxFiles: 5,
+		FilePath: dir,
+		FileName: "stella-go.log",
+	}
+	writer, err := logger.NewConfigRotateWriter(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	writer.Write([]byte("1234567890"))
+	writer.Write([]byte("1234567890"))
+	if stats := writer.Stats(); stats.Rotations != 0 {
+		t.Fatalf("unexpected rotations %d", stats.Rotations)
+	}
+
+	resized := *config
+	resized.MaxFileSize = 5 * logger.FileSizeB
+	if err := writer.SetConfig(&resized); err != nil {
+		t.Fatal(err)
+	}
+	writer.Write([]byte("1234567890"))
+	if stats := writer.Stats(); stats.Rotations != 1 {
+		t.Fatalf("unexpected rotations %d", stats.Rotations)
+	}
+
+	renamed := resized
+	renamed.FileName = "renamed.log"
+	if err := writer.SetConfig(&renamed); err != nil {
+		t.Fatal(err)
+	}
+	writer.Write([]byte("abc"))
+	if b, _ := os.ReadFile(path.Join(dir, "renamed.log")); string(b) != "abc" {
+		t.Fatalf("unexpected content %q", b)
+	}
+
+	invalid := renamed
+	invalid.MaxFiles = -1
+	if err := writer.SetConfig(&invalid); err == nil {
+		t.Fatal("expected an error for a negative MaxFiles")
+	}
+}
+
+func TestDatedActiveFile(t *testing.T) {
+	dir := t.TempDir()
+	expired := path.Join(dir, "app-20000101.log")
+	other := path.Join(dir, "app-error.log")
+	for _, p := range []string{expired, other} {
+		if err := os.WriteFile(p, []byte("old"), 0644); err != nil {
+			t.Fatal(err)
+		}
+		old := time.Now().Add(-48 * time.Hour)
+		os.Chtimes(p, old, old)
+	}
+	var oldPath, newPath string
+	east, west := time.FixedZone("east", 14*3600), time.FixedZone("west", -12*3600)
+	config := &logger.RotateConfig{
+		MaxFiles:        2,
+		FilePath:        dir,
+		FileName:        "app.log",
+		DatedActiveFile: true,
+		Location:        east,
+		OnRotate: func(o, n string) {
+			oldPath, newPath = o, n
+		},
+	}
+	writer, err := logger.NewConfigRotateWriter(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	writer.Write([]byte("east"))
+
+	// the two zones are 26 hours apart, so switching the zone crosses a day boundary
+	switched := *config
+	switched.Location = west
+	writer.SetConfig(&switched)
+	writer.Write([]byte("west"))
+
+	eastPath := path.Join(dir, "app-"+time.Now().In(east).Format("20060102")+".log")
+	westPath := path.Join(dir, "app-"+time.Now().In(west).Format("20060102")+".log")
+	if b, _ := os.ReadFile(eastPath); string(b) != "east" {
+		t.Fatalf("unexpected content %q", b)
+	}
+	if b, _ := os.ReadFile(westPath); string(b) != "west" {
+		t.Fatalf("unexpected content %q", b)
+	}
+	if oldPath != westPath || newPath != eastPath {
+		t.Fatalf("unexpected rotation %s -> %s", oldPath, newPath)
+	}
+	if _, err := os.Stat(expired); !os.IsNotExist(err) {
+		t.Fatalf("expected %s to be removed", expired)
+	}
+	if _, err := os.Stat(other); err != nil {
+		t.Fatalf("expected %s to be kept: %v", other, err)
+	}
+}
+
+func TestNewFileRotateWriter(t *testing.T) {
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	writer := logger.NewFileRotateWriter(w, &logger.RotateConfig{Enable: true, MaxFileSize: 1})
+	writer.Write([]byte("abc"))
+	writer.Write([]byte("def"))
+	w.Close()
+	if b, _ := io.ReadAll(r); string(b) != "abcdef" {
+		t.Fatalf("unexpected content %q", b)
+	}
+
+	dir := t.TempDir()
+	f, err := os.Create(path.Join(dir, "stella-go.log"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	writer = logger.NewFileRotateWriter(f, &logger.RotateConfig{MaxFiles: 5, FilePath: dir, FileName: "stella-go.log"})
+	writer.Write([]byte("abc"))
+	if err := writer.Rotate(); err != nil {
+		t.Fatal(err)
+	}
+	writer.Write([]byte("def"))
+	if b, _ := os.ReadFile(path.Join(dir, "stella-go.log")); string(b) != "def" {
+		t.Fatalf("unexpected active content %q", b)
+	}
+}
+
+func TestMaxFiles(t *testing.T) {
+	for maxFiles, expected := range map[int]int{0: 4, 1: 1, 2: 2, 3: 3} {
+		dir := t.TempDir()
+		writer, err := logger.NewConfigRotateWriter(&logger.RotateConfig{
+			Enable:      true,
+			MaxFiles:    maxFiles,
+			MaxFileSize: 5 * logger.FileSizeB,
+			FilePath:    dir,
+			FileName:    "stella-go.log",
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		for i := 0; i < 4; i++ {
+			writer.Write([]byte("1234567890"))
+		}
+		entries, _ := os.ReadDir(dir)
+		if len(entries) != expected {
+			t.Fatalf("MaxFiles %d: expected %d files, got %d", maxFiles, expected, len(entries))
+		}
+		if b, _ := os.ReadFile(path.Join(dir, "stella-go.log")); string(b) != "1234567890" {
+			t.Fatalf("MaxFiles %d: unexpected active content %q", maxFiles, b)
+		}
+	}
+
+	_, err := logger.NewConfigRotateWriter(&logger.RotateConfig{MaxFiles: -1, FilePath: t.TempDir(), FileName: "stella-go.log"})
+	if err == nil {
+		t.Fatal("expected an error for a negative MaxFiles")
+	}
+}
+
+type FailingFS struct {
+	logger.OSFileSystem
+	renameErr error
+	removeErr error
+}
+
+func (fs *FailingFS) Rename(oldPath string, newPath string) error {
+	if fs.renameErr != nil {
+		return fs.renameErr
+	}
+	return fs.OSFileSystem.Rename(oldPath, newPath)
+}
+
+func (fs *FailingFS) Remove(name string) error {
+	if fs.removeErr != nil {
+		return fs.removeErr
+	}
+	return fs.OSFileSystem.Remove(name)
+}
+
+func TestRotateRenameError(t *testing.T) {
+	dir := t.TempDir()
+	fs := &FailingFS{renameErr: errors.New("rename failed")}
+	writer, err := logger.NewConfigRotateWriterFS(&logger.RotateConfig{MaxFiles: 2, FilePath: dir, FileName: "stella-go.log"}, fs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	writer.Write([]byte("abc"))
+	if err := writer.Rotate(); !errors.Is(err, fs.renameErr) {
+		t.Fatalf("unexpected error %v", err)
+	}
+	writer.Write([]byte("def"))
+	if b, _ := os.ReadFile(path.Join(dir, "stella-go.log")); string(b) != "abcdef" {
+		t.Fatalf("unexpected content %q", b)
+	}
+	if stats := writer.Stats(); stats.Rotations != 0 {
+		t.Fatalf("unexpected rotations %d", stats.Rotations)
+	}
+}
+
+func TestRotateRemoveError(t *testing.T) {
+	dir := t.TempDir()
+	fs := &FailingFS{removeErr: errors.New("remove failed")}
+	writer, err := logger.NewConfigRotateWriterFS(&logger.RotateConfig{MaxFiles: 2, FilePath: dir, FileName: "stella-go.log"}, fs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 3; i++ {
+		writer.Write([]byte("abc"))
+		if err := writer.Rotate(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if entries, _ := os.ReadDir(dir); len(entries) != 4 {
+		t.Fatalf("expected the archives to be kept, got %d files", len(entries))
+	}
+	if stats := writer.Stats(); stats.Rotations != 3 {
+		t.Fatalf("unexpected rotations %d", stats.Rotations)
+	}
+}
+
+func TestParseSize(t *testing.T) {
+	cases := map[string]int64{
+		"1.5G":  3 * logger.FileSizeG / 2,
+		"512k":  512 * logger.FileSizeK,
+		"200MB": 200 * logger.FileSizeM,
+		"10kb":  10 * logger.FileSizeK,
+		"100":   100,
+		"64B":   64,
+	}
+	for s, expected := range cases {
+		n, err := logger.ParseSize(s)
+		if err != nil || n != expected {
+			t.Fatalf("ParseSize(%q) = %d, %v", s, n, err)
+		}
+	}
+	for _, s := range []string{"", "G", "abc", "1.5X", "-1M", "1MM", "9999999999G", "9223372036854775808"} {
+		if _, err := logger.ParseSize(s); err == nil {
+			t.Fatalf("ParseSize(%q) should fail", s)
+		}
+	}
+	if _, err := logger.ParseSize("9999999999G"); err == nil || !strings.Contains(err.Error(), "too large") {
+		t.Fatalf("unexpected error %v", err)
+	}
+}
+
+func TestFormatSize(t *testing.T) {
+	cases := map[int64]string{
+		3 * logger.FileSizeG / 2: "1.5G",
+		512 * logger.FileSizeK:   "512K",
+		200 * logger.FileSizeM:   "200M",
+		100:                      "100B",
+	}
+	for n, expected := range cases {
+		if s := logger.FormatSize(n); s != expected {
+			t.Fatalf("FormatSize(%d) = %s", n, s)
+		}
+		if parsed, _ := logger.ParseSize(logger.FormatSize(n)); parsed != n {
+			t.Fatalf("FormatSize(%d) does not round trip", n)
+		}
+	}
+}
+
+func TestRotateLocation(t *testing.T) {
+	dir := t.TempDir()
+	zone := time.FixedZone("UTC+14", 14*60*60)
+	writer, err := logger.NewConfigRotateWriter(&logger.RotateConfig{
+		MaxFiles: 5,
+		FilePath: dir,
+		FileName: "stella-go.log",
+		Location: zone,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	writer.Write([]byte("abc"))
+	mtime := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	os.Chtimes(path.Join(dir, "stella-go.log"), mtime, mtime)
+	if err := writer.Rotate(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(path.Join(dir, "stella-go.log.20250602.1")); err != nil {
+		t.Fatalf("archive not named after the configured zone: %v", err)
+	}
+}
@@ -0,0 +1,117 @@
+// Copyright 2010-2025 the original author or authors.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+
+// 	http://www.apache.org/licenses/LICENSE-2.0
+
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logger
+
+import (
+	"container/list"
+	"io"
+	"sync"
+)
+
+// RoutingWriter sends each entry to the writer of its key, such as a file per tenant,
+// creating the writers on first use. At most MaxWriters stay open, the least recently
+// used one is closed, when an io.Closer, to make room and created again when its key
+// comes back. Raw writes through Logger.Write go to the writer of the empty key.
+type RoutingWriter struct {
+	MaxWriters int // default 64, set it before the first write
+
+	keyFn   func(*Entry) string
+	factory func(key string) io.Writer
+	writers map[string]*list.Element
+	lru     *list.List // most recently used first
+	lock    sync.Mutex
+}
+
+type routedWriter struct {
+	key    string
+	writer io.Writer
+}
+
+// NewRoutingWriter routes entries by keyFn to the writers made by factory, for example:
+//
+//	NewRoutingWriter(func(e *Entry) string {
+//		return fmt.Sprint(e.Fields["tenant"])
+//	}, func(key string) io.Writer {
+//		w, _ := NewRotateWriter("files", key+".log")
+//		return w
+//	})
+func NewRoutingWriter(keyFn func(*Entry) string, factory func(key string) io.Writer) *RoutingWriter {
+	return &RoutingWriter{
+		keyFn:   keyFn,
+		factory: factory,
+		writers: make(map[string]*list.Element),
+		lru:     list.New(),
+	}
+}
+
+func (w *RoutingWriter) Write(p []byte) (int, error) {
+	return w.write("", nil, p)
+}
+
+func (w *RoutingWriter) WriteEntry(e *Entry, p []byte) (int, error) {
+	return w.write(w.keyFn(e), e, p)
+}
+
+func (w *RoutingWriter) write(key string, e *Entry, p []byte) (int, error) {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	writer := w.writer(key)
+	if writer == nil {
+		return len(p), nil
+	}
+	return writeEntry(writer, e, p)
+}
+
+// writer returns the writer of key, creating it and closing the least recently used one
+// when needed. A nil writer from the factory discards the key's entries.
+func (w *RoutingWriter) writer(key string) io.Writer {
+	if elem, ok := w.writers[key]; ok {
+		w.lru.MoveToFront(elem)
+		return elem.Value.(*routedWriter).writer
+	}
+	max := w.MaxWriters
+	if max <= 0 {
+		max = 64
+	}
+	for w.lru.Len() >= max {
+		oldest := w.lru.Remove(w.lru.Back()).(*routedWriter)
+		delete(w.writers, oldest.key)
+		if c, ok := oldest.writer.(io.Closer); ok {
+			if err := c.Close(); err != nil {
+				print("RoutingWriter", "ERROR", "Close writer %q error: %v", oldest.key, err)
+			}
+		}
+	}
+	writer := w.factory(key)
+	w.writers[key] = w.lru.PushFront(&routedWriter{key: key, writer: writer})
+	return writer
+}
+
+// Close closes the open writers that are an io.Closer and returns the first error.
+func (w *RoutingWriter) Close() error {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	var err error
+	for elem := w.lru.Front(); elem != nil; elem = elem.Next() {
+		if c, ok := elem.Value.(*routedWriter).writer.(io.Closer); ok {
+			if e := c.Close(); e != nil && err == nil {
+				err = e
+			}
+		}
+	}
+	w.writers = make(map[string]*list.Element)
+	w.lru.Init()
+	return err
+}
@@ -0,0 +1,78 @@
+// Copyright 2010-2025 the original author or authors.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+
+// 	http://www.apache.org/licenses/LICENSE-2.0
+
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logger_test
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"reflect"
+	"testing"
+
+	"github.com/stella-go/logger"
+)
+
+type routedBuffer struct {
+	bytes.Buffer
+	closed bool
+}
+
+func (b *routedBuffer) Close() error {
+	b.closed = true
+	return nil
+}
+
+func TestRoutingWriter(t *testing.T) {
+	var created []string
+	buffers := map[string][]*routedBuffer{}
+	writer := logger.NewRoutingWriter(func(e *logger.Entry) string {
+		return fmt.Sprint(e.Fields["tenant"])
+	}, func(key string) io.Writer {
+		created = append(created, key)
+		b := &routedBuffer{}
+		buffers[key] = append(buffers[key], b)
+		return b
+	})
+	writer.MaxWriters = 2
+	rootLogger := logger.NewRootLogger(logger.InfoLevel, &NopFormatter{}, writer)
+	acme := rootLogger.WithFields(map[string]interface{}{"tenant": "acme"})
+	globex := rootLogger.WithFields(map[string]interface{}{"tenant": "globex"})
+	initech := rootLogger.WithFields(map[string]interface{}{"tenant": "initech"})
+
+	acme.INFO("a1\n")
+	globex.INFO("g1\n")
+	acme.INFO("a2\n")
+	initech.INFO("i1\n")
+	globex.INFO("g2\n")
+
+	if !reflect.DeepEqual(created, []string{"acme", "globex", "initech", "globex"}) {
+		t.Fatalf("unexpected writers %v", created)
+	}
+	if b := buffers["globex"][0]; b.String() != "g1\n" || !b.closed {
+		t.Fatalf("unexpected evicted globex writer %q %v", b.String(), b.closed)
+	}
+	if b := buffers["acme"][0]; b.String() != "a1\na2\n" || !b.closed {
+		t.Fatalf("unexpected evicted acme writer %q %v", b.String(), b.closed)
+	}
+	if b := buffers["initech"][0]; b.String() != "i1\n" || b.closed {
+		t.Fatalf("unexpected initech writer %q %v", b.String(), b.closed)
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if b := buffers["globex"][1]; b.String() != "g2\n" || !b.closed || !buffers["initech"][0].closed {
+		t.Fatalf("unexpected globex writer %q %v", b.String(), b.closed)
+	}
+}
@@ -0,0 +1,59 @@
+// Copyright 2010-2025 the original author or authors.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+
+// 	http://www.apache.org/licenses/LICENSE-2.0
+
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logger
+
+import (
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+)
+
+var shutdownOnce sync.Once
+
+// HandleShutdown flushes the default logger when one of signals arrives, SIGINT and SIGTERM
+// when none are given, so lines buffered by its writers are not lost. The writer stays
+// open: goroutines still logging during a graceful shutdown keep working.
+//
+// It does not call os.Exit, but catching a signal disables its default action, so once
+// flushed the handler stops listening and raises the signal again. Without handlers of its
+// own the process then terminates as it would have without HandleShutdown; the handlers
+// of an application handling the signal itself see it twice, such applications should call
+// Flush from their handler instead. Only the first call installs the handler.
+func HandleShutdown(signals ...os.Signal) {
+	shutdownOnce.Do(func() {
+		installShutdown(signals...)
+	})
+}
+
+// installShutdown installs a shutdown handler for signals on every call.
+func installShutdown(signals ...os.Signal) {
+	xInit()
+	if len(signals) == 0 {
+		signals = []os.Signal{os.Interrupt, syscall.SIGTERM}
+	}
+	ch := make(chan os.Signal, 1)
+	signal.Notify(ch, signals...)
+	go func() {
+		sig := <-ch
+		if err := defaultRootLogger.Flush(); err != nil {
+			print("Logger", "ERROR", "Flush error: %v", err)
+		}
+		signal.Stop(ch)
+		if p, err := os.FindProcess(os.Getpid()); err == nil {
+			p.Signal(sig)
+		}
+	}()
+}
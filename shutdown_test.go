@@ -0,0 +1,63 @@
+// Copyright 2010-2025 the original author or authors.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+
+// 	http://www.apache.org/licenses/LICENSE-2.0
+
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build linux || darwin
+// +build linux darwin
+
+package logger_test
+
+import (
+	"bytes"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stella-go/logger"
+)
+
+type FlushRecorder struct {
+	bytes.Buffer
+	once    sync.Once
+	flushed chan struct{}
+	closed  int32
+}
+
+func (r *FlushRecorder) Flush() error {
+	r.once.Do(func() { close(r.flushed) })
+	return nil
+}
+
+func (r *FlushRecorder) Close() error {
+	atomic.StoreInt32(&r.closed, 1)
+	return nil
+}
+
+func TestHandleShutdown(t *testing.T) {
+	recorder := &FlushRecorder{flushed: make(chan struct{})}
+	defer logger.SetDefaultRootLogger(logger.NewRootLogger(logger.InfoLevel, &NopFormatter{}, recorder))()
+
+	// SIGWINCH is ignored by default, so raising it again does not stop the test
+	logger.InstallShutdown(syscall.SIGWINCH)
+	syscall.Kill(syscall.Getpid(), syscall.SIGWINCH)
+	select {
+	case <-recorder.flushed:
+	case <-time.After(5 * time.Second):
+		t.Fatal("the default logger was not flushed")
+	}
+	if atomic.LoadInt32(&recorder.closed) != 0 {
+		t.Fatal("the default logger should stay open")
+	}
+}
@@ -0,0 +1,62 @@
+// Copyright 2010-2025 the original author or authors.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+
+// 	http://www.apache.org/licenses/LICENSE-2.0
+
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build go1.21
+// +build go1.21
+
+package logger_test
+
+import (
+	"bytes"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/stella-go/logger"
+)
+
+type FieldsFormatter struct{}
+
+func (*FieldsFormatter) Format(e *logger.Entry) []byte {
+	keys := make([]string, 0, len(e.Fields))
+	for k := range e.Fields {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	pairs := make([]string, 0, len(keys))
+	for _, k := range keys {
+		pairs = append(pairs, fmt.Sprintf("%s=%v", k, e.Fields[k]))
+	}
+	return []byte(fmt.Sprintf("%s %s %s [%s]\n", e.Level.String(), e.Tag, e.Message, strings.Join(pairs, " ")))
+}
+
+func TestSlogHandler(t *testing.T) {
+	buf := &bytes.Buffer{}
+	rootLogger := logger.NewRootLogger(logger.InfoLevel, &FieldsFormatter{}, buf)
+	log := slog.New(logger.NewSlogHandler(rootLogger.GetLogger("Slog")))
+
+	log.Debug("hidden")
+	log.Info("hello", "user", "bob", "id", 7)
+	log.With("service", "api").WithGroup("req").Warn("slow", "ms", 512, slog.Group("peer", "ip", "127.0.0.1"))
+	log.Error("failed", slog.Group("", "inline", true))
+
+	expected := "INFO  Slog hello [id=7 user=bob]\n" +
+		"WARN  Slog slow [req.ms=512 req.peer.ip=127.0.0.1 service=api]\n" +
+		"ERROR Slog failed [inline=true]\n"
+	if buf.String() != expected {
+		t.Fatalf("unexpected output:\n%s", buf.String())
+	}
+}
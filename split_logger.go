@@ -0,0 +1,63 @@
+// Copyright 2010-2025 the original author or authors.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+
+// 	http://www.apache.org/licenses/LICENSE-2.0
+
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logger
+
+import (
+	"os"
+	"path"
+	"strings"
+)
+
+type SplitConfig struct {
+	RotateConfig
+	ErrorFileName string // defaults to FileName with "-error" before the extension
+	ErrorLevel    Level  // lowest level also written to the error file
+}
+
+// NewSplitLogger writes every entry to dir/app.log and entries at ERROR or above also to dir/app-error.log.
+// When the files cannot be opened it prints the error and writes to stdout instead.
+func NewSplitLogger(dir string, level Level) *Logger {
+	config := &SplitConfig{
+		RotateConfig: *defaultRotateConfig(dir, "app.log"),
+		ErrorLevel:   ErrorLevel,
+	}
+	logger, err := NewConfigSplitLogger(level, config)
+	if err != nil {
+		print("Logger", "ERROR", "Open rotate writer error: %v, use stdout", err)
+		return NewRootLogger(level, &DefaultFormatter{}, os.Stdout)
+	}
+	return logger
+}
+
+func NewConfigSplitLogger(level Level, config *SplitConfig) (*Logger, error) {
+	allConfig := config.RotateConfig
+	writer, err := NewConfigRotateWriter(&allConfig)
+	if err != nil {
+		return nil, err
+	}
+	errorConfig := config.RotateConfig
+	errorConfig.FileName = config.ErrorFileName
+	if errorConfig.FileName == "" {
+		ext := path.Ext(config.FileName)
+		errorConfig.FileName = strings.TrimSuffix(config.FileName, ext) + "-error" + ext
+	}
+	errorWriter, err := NewConfigRotateWriter(&errorConfig)
+	if err != nil {
+		writer.Close()
+		return nil, err
+	}
+	sink := NewMultiSink(Sink{Writer: writer, Level: TraceLevel}, Sink{Writer: errorWriter, Level: config.ErrorLevel})
+	return NewRootLogger(level, &DefaultFormatter{}, sink), nil
+}
@@ -0,0 +1,72 @@
+// Copyright 2010-2025 the original author or authors.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+
+// 	http://www.apache.org/licenses/LICENSE-2.0
+
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logger_test
+
+import (
+	"os"
+	"path"
+	"strings"
+	"testing"
+
+	"github.com/stella-go/logger"
+)
+
+func TestNewSplitLogger(t *testing.T) {
+	dir := t.TempDir()
+	splitLogger := logger.NewSplitLogger(dir, logger.InfoLevel)
+	splitLogger.INFO("info")
+	splitLogger.WARN("warn")
+	splitLogger.ERROR("error")
+
+	all, _ := os.ReadFile(path.Join(dir, "app.log"))
+	if strings.Count(string(all), "\n") != 3 {
+		t.Fatalf("unexpected app.log:\n%s", all)
+	}
+	errors, _ := os.ReadFile(path.Join(dir, "app-error.log"))
+	if strings.Count(string(errors), "\n") != 1 || !strings.Contains(string(errors), "ERROR ROOT - error") {
+		t.Fatalf("unexpected app-error.log:\n%s", errors)
+	}
+}
+
+func TestNewConfigSplitLogger(t *testing.T) {
+	dir := t.TempDir()
+	splitLogger, err := logger.NewConfigSplitLogger(logger.InfoLevel, &logger.SplitConfig{
+		RotateConfig: logger.RotateConfig{
+			FilePath: dir,
+			FileName: "service.log",
+		},
+		ErrorFileName: "service.warn.log",
+		ErrorLevel:    logger.WarnLevel,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	splitLogger.INFO("info")
+	splitLogger.WARN("warn")
+
+	errors, _ := os.ReadFile(path.Join(dir, "service.warn.log"))
+	if strings.Count(string(errors), "\n") != 1 || !strings.Contains(string(errors), "WARN  ROOT - warn") {
+		t.Fatalf("unexpected service.warn.log:\n%s", errors)
+	}
+}
+
+func TestNewSplitLoggerFallback(t *testing.T) {
+	file := path.Join(t.TempDir(), "file")
+	os.WriteFile(file, nil, 0644)
+	splitLogger := logger.NewSplitLogger(path.Join(file, "logs"), logger.WarnLevel)
+	if splitLogger == nil || splitLogger.Writer() != os.Stdout || splitLogger.Level() != logger.WarnLevel {
+		t.Fatal("expected a stdout logger when the files cannot be opened")
+	}
+}
@@ -0,0 +1,95 @@
+// Copyright 2010-2025 the original author or authors.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+
+// 	http://www.apache.org/licenses/LICENSE-2.0
+
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logger
+
+import (
+	"sync/atomic"
+)
+
+type Stats struct {
+	Lines     int64
+	Bytes     int64
+	Rotations int64
+	Errors    int64
+	Dropped   int64
+	QueueFull int64
+	QueueLen  int
+	QueueCap  int
+	// DroppedNewest and DroppedOldest split the lines an AsyncWriter dropped by overflow
+	// policy, they are also counted in Dropped.
+	DroppedNewest int64
+	DroppedOldest int64
+}
+
+// stats must be the first field of its owner to keep the counters 64-bit aligned on 32-bit platforms.
+type stats struct {
+	lines     int64
+	bytes     int64
+	rotations int64
+	errors    int64
+	dropped   int64
+	queueFull int64
+	newest    int64
+	oldest    int64
+}
+
+func (s *stats) written(n int, err error) {
+	s.writtenLines(1, n, err)
+}
+
+func (s *stats) writtenLines(lines int, n int, err error) {
+	if err != nil {
+		atomic.AddInt64(&s.errors, 1)
+		return
+	}
+	atomic.AddInt64(&s.lines, int64(lines))
+	atomic.AddInt64(&s.bytes, int64(n))
+}
+
+func (s *stats) full() {
+	atomic.AddInt64(&s.queueFull, 1)
+}
+
+func (s *stats) rotated() {
+	atomic.AddInt64(&s.rotations, 1)
+}
+
+func (s *stats) drop() {
+	atomic.AddInt64(&s.dropped, 1)
+}
+
+func (s *stats) dropNewest() {
+	atomic.AddInt64(&s.dropped, 1)
+	atomic.AddInt64(&s.newest, 1)
+}
+
+func (s *stats) dropOldest() {
+	atomic.AddInt64(&s.dropped, 1)
+	atomic.AddInt64(&s.oldest, 1)
+}
+
+func (s *stats) snapshot() Stats {
+	return Stats{
+		Lines:     atomic.LoadInt64(&s.lines),
+		Bytes:     atomic.LoadInt64(&s.bytes),
+		Rotations: atomic.LoadInt64(&s.rotations),
+		Errors:    atomic.LoadInt64(&s.errors),
+		Dropped:   atomic.LoadInt64(&s.dropped),
+		QueueFull: atomic.LoadInt64(&s.queueFull),
+
+		DroppedNewest: atomic.LoadInt64(&s.newest),
+		DroppedOldest: atomic.LoadInt64(&s.oldest),
+	}
+}
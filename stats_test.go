@@ -0,0 +1,165 @@
+// Copyright 2010-2025 the original author or authors.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+
+// 	http://www.apache.org/licenses/LICENSE-2.0
+
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logger_test
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"io/ioutil"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stella-go/logger"
+)
+
+type ErrorWriter struct{}
+
+func (*ErrorWriter) Write(p []byte) (int, error) {
+	return 0, errors.New("write failed")
+}
+
+func TestLoggerStats(t *testing.T) {
+	rootLogger := logger.NewRootLogger(logger.InfoLevel, &NopFormatter{}, &bytes.Buffer{})
+	rootLogger.DEBUG("12345")
+	rootLogger.INFO("12345")
+	rootLogger.GetLogger("Stats").WARN("1234567890")
+	stats := rootLogger.Stats()
+	if stats.Lines != 2 || stats.Bytes != 15 || stats.Errors != 0 {
+		t.Fatalf("unexpected stats %+v", stats)
+	}
+
+	errorLogger := logger.NewRootLogger(logger.InfoLevel, &NopFormatter{}, &ErrorWriter{})
+	errorLogger.INFO("12345")
+	if stats := errorLogger.Stats(); stats.Lines != 0 || stats.Errors != 1 {
+		t.Fatalf("unexpected stats %+v", stats)
+	}
+}
+
+func TestRotateWriterStats(t *testing.T) {
+	writer, err := logger.NewConfigRotateWriter(&logger.RotateConfig{
+		Enable:      true,
+		MaxFiles:    5,
+		MaxFileSize: 5 * logger.FileSizeB,
+		FilePath:    t.TempDir(),
+		FileName:    "stella-go.log",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 3; i++ {
+		writer.Write([]byte("1234567890"))
+	}
+	if stats := writer.Stats(); stats.Lines != 3 || stats.Bytes != 30 || stats.Rotations != 2 {
+		t.Fatalf("unexpected stats %+v", stats)
+	}
+}
+
+type BlockingWriter struct {
+	release chan struct{}
+	calls   int32
+}
+
+func (w *BlockingWriter) Write(p []byte) (int, error) {
+	atomic.AddInt32(&w.calls, 1)
+	<-w.release
+	return len(p), nil
+}
+
+func TestWriteTimeout(t *testing.T) {
+	writer := &BlockingWriter{release: make(chan struct{})}
+	defer close(writer.release)
+	rootLogger := logger.NewRootLogger(logger.InfoLevel, &NopFormatter{}, writer)
+	rootLogger.SetWriteTimeout(10 * time.Millisecond)
+
+	start := time.Now()
+	rootLogger.INFO("12345")
+	if _, err := rootLogger.Write([]byte("12345")); err != logger.ErrWriteTimeout {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatalf("write blocked for %v", elapsed)
+	}
+	if stats := rootLogger.Stats(); stats.Dropped != 2 || stats.Errors != 0 {
+		t.Fatalf("unexpected stats %+v", stats)
+	}
+	if calls := atomic.LoadInt32(&writer.calls); calls != 1 {
+		t.Fatalf("writer called %d times while stalled", calls)
+	}
+}
+
+func TestWriteTimeoutRecovers(t *testing.T) {
+	writer := &BlockingWriter{release: make(chan struct{})}
+	rootLogger := logger.NewRootLogger(logger.InfoLevel, &NopFormatter{}, writer)
+	rootLogger.SetWriteTimeout(10 * time.Millisecond)
+	reports := make(chan error, 2)
+	rootLogger.SetOnError(func(err error) { reports <- err })
+
+	if _, err := rootLogger.Write([]byte("12345")); err != logger.ErrWriteTimeout {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if err := <-reports; err != logger.ErrWriteStalled {
+		t.Fatalf("unexpected report %v", err)
+	}
+	close(writer.release)
+	if err := <-reports; err != logger.ErrWriteResumed {
+		t.Fatalf("unexpected report %v", err)
+	}
+	deadline := time.Now().Add(time.Second)
+	for {
+		if _, err := rootLogger.Write([]byte("12345")); err == nil {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("writes still dropped after the writer returned")
+		}
+		time.Sleep(time.Millisecond)
+	}
+	if calls := atomic.LoadInt32(&writer.calls); calls != 2 {
+		t.Fatalf("unexpected writer calls %d", calls)
+	}
+}
+
+type BlockingReaderFrom struct {
+	BlockingWriter
+}
+
+func (w *BlockingReaderFrom) ReadFrom(r io.Reader) (int64, error) {
+	atomic.AddInt32(&w.calls, 1)
+	<-w.release
+	return io.Copy(ioutil.Discard, r)
+}
+
+func TestWriteTimeoutReadFrom(t *testing.T) {
+	writer := &BlockingReaderFrom{BlockingWriter{release: make(chan struct{})}}
+	defer close(writer.release)
+	rootLogger := logger.NewRootLogger(logger.InfoLevel, &NopFormatter{}, writer)
+	rootLogger.SetWriteTimeout(10 * time.Millisecond)
+
+	if _, err := rootLogger.ReadFrom(strings.NewReader("12345")); err != logger.ErrWriteTimeout {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if _, err := rootLogger.ReadFrom(strings.NewReader("12345")); err != logger.ErrWriteTimeout {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if calls := atomic.LoadInt32(&writer.calls); calls != 1 {
+		t.Fatalf("writer called %d times while stalled", calls)
+	}
+	if stats := rootLogger.Stats(); stats.Dropped != 2 {
+		t.Fatalf("unexpected stats %+v", stats)
+	}
+}
@@ -0,0 +1,77 @@
+// Copyright 2010-2025 the original author or authors.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+
+// 	http://www.apache.org/licenses/LICENSE-2.0
+
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logger
+
+import (
+	"fmt"
+	"log"
+)
+
+// RedirectStdLog sends the output of the standard log package to l at the given level,
+// the returned function restores the previous output and flags.
+func RedirectStdLog(l *Logger, level Level) func() {
+	output := log.Writer()
+	flags := log.Flags()
+	log.SetOutput(&levelWriter{logger: l, level: level, strict: true})
+	log.SetFlags(0)
+	return func() {
+		log.SetOutput(output)
+		log.SetFlags(flags)
+	}
+}
+
+// StdLogger returns a standard *log.Logger writing through l at the given level, for code
+// that only accepts *log.Logger. Its flags are cleared so the formatter owns the layout.
+// log.Logger ignores write errors, so they go to the strict mode like those of the level methods.
+func (l *Logger) StdLogger(level Level) *log.Logger {
+	return log.New(&levelWriter{logger: l, level: level, strict: true}, "", 0)
+}
+
+// StdLoggerAdapter implements the Print/Printf/Println logger interface
+// expected by many libraries, writing through l at a fixed level.
+type StdLoggerAdapter struct {
+	logger *Logger
+	level  Level
+}
+
+func NewStdLoggerAdapter(l *Logger, level Level) *StdLoggerAdapter {
+	return &StdLoggerAdapter{
+		logger: l,
+		level:  level,
+	}
+}
+
+func (a *StdLoggerAdapter) Print(v ...interface{}) {
+	a.log(fmt.Sprint(v...))
+}
+
+func (a *StdLoggerAdapter) Printf(format string, v ...interface{}) {
+	a.log(fmt.Sprintf(format, v...))
+}
+
+func (a *StdLoggerAdapter) Println(v ...interface{}) {
+	a.log(sprintln(v...))
+}
+
+func (a *StdLoggerAdapter) log(msg string) {
+	entry := &Entry{
+		Tag:     a.logger.tag,
+		Level:   a.level,
+		Message: msg,
+		Caller:  callerPC(2),
+		Fields:  a.logger.fields,
+	}
+	a.logger.logEntry(entry)
+}
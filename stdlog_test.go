@@ -0,0 +1,76 @@
+// Copyright 2010-2025 the original author or authors.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+
+// 	http://www.apache.org/licenses/LICENSE-2.0
+
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logger_test
+
+import (
+	"bytes"
+	"log"
+	"os"
+	"testing"
+
+	"github.com/stella-go/logger"
+)
+
+type LineFormatter struct{}
+
+func (*LineFormatter) Format(e *logger.Entry) []byte {
+	return []byte(e.Level.String() + " " + e.Tag + " - " + e.Message + "\n")
+}
+
+func TestRedirectStdLog(t *testing.T) {
+	buf := &bytes.Buffer{}
+	rootLogger := logger.NewRootLogger(logger.InfoLevel, &LineFormatter{}, buf)
+	restore := logger.RedirectStdLog(rootLogger.GetLogger("Std"), logger.WarnLevel)
+	log.Println("from stdlib")
+	restore()
+
+	if buf.String() != "WARN  Std - from stdlib\n" {
+		t.Fatalf("unexpected output: %q", buf.String())
+	}
+	if log.Writer() != os.Stderr || log.Flags() != log.LstdFlags {
+		t.Fatal("standard logger not restored")
+	}
+}
+
+func TestStdLogger(t *testing.T) {
+	buf := &bytes.Buffer{}
+	rootLogger := logger.NewRootLogger(logger.InfoLevel, &LineFormatter{}, buf)
+	stdLogger := rootLogger.GetLogger("Std").StdLogger(logger.ErrorLevel)
+	stdLogger.Printf("failed %d times", 3)
+	rootLogger.GetLogger("Std").StdLogger(logger.DebugLevel).Print("hidden")
+
+	if buf.String() != "ERROR Std - failed 3 times\n" {
+		t.Fatalf("unexpected output: %q", buf.String())
+	}
+}
+
+func TestStdLoggerAdapter(t *testing.T) {
+	buf := &bytes.Buffer{}
+	rootLogger := logger.NewRootLogger(logger.InfoLevel, &LineFormatter{}, buf)
+	adapter := logger.NewStdLoggerAdapter(rootLogger.GetLogger("Kafka"), logger.InfoLevel)
+	adapter.Print("connected ", "broker-1")
+	adapter.Printf("partition %d", 3)
+	adapter.Println("closing", "client")
+
+	debugAdapter := logger.NewStdLoggerAdapter(rootLogger.GetLogger("Kafka"), logger.DebugLevel)
+	debugAdapter.Println("hidden")
+
+	expected := "INFO  Kafka - connected broker-1\n" +
+		"INFO  Kafka - partition 3\n" +
+		"INFO  Kafka - closing client\n"
+	if buf.String() != expected {
+		t.Fatalf("unexpected output:\n%s", buf.String())
+	}
+}
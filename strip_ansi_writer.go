@@ -0,0 +1,108 @@
+// Copyright 2010-2025 the original author or authors.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+
+// 	http://www.apache.org/licenses/LICENSE-2.0
+
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logger
+
+import (
+	"io"
+	"sync"
+)
+
+const (
+	ansiText = iota
+	ansiEscape
+	ansiCSI
+	ansiOSC
+	ansiOSCEscape
+)
+
+// StripANSIWriter removes ANSI escape sequences, such as colors, before writing to the
+// underlying writer, so a colored console line can also go to a plain file:
+//
+//	NewMultiSink(Sink{Writer: os.Stdout}, Sink{Writer: NewStripANSIWriter(file)})
+//
+// A sequence split across writes is still removed.
+type StripANSIWriter struct {
+	writer io.Writer
+	state  int
+	buf    []byte
+	lock   sync.Mutex
+}
+
+func NewStripANSIWriter(w io.Writer) *StripANSIWriter {
+	return &StripANSIWriter{
+		writer: w,
+	}
+}
+
+// Write returns len(p) when the stripped bytes are written, as they all were consumed.
+func (w *StripANSIWriter) Write(p []byte) (int, error) {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	w.buf = w.strip(w.buf[:0], p)
+	if len(w.buf) > 0 {
+		if _, err := w.writer.Write(w.buf); err != nil {
+			return 0, err
+		}
+	}
+	return len(p), nil
+}
+
+// strip appends the text of p to b, the state carries over to the next write.
+func (w *StripANSIWriter) strip(b []byte, p []byte) []byte {
+	for _, c := range p {
+		switch w.state {
+		case ansiText:
+			if c == 0x1b {
+				w.state = ansiEscape
+			} else {
+				b = append(b, c)
+			}
+		case ansiEscape:
+			switch c {
+			case '[':
+				w.state = ansiCSI
+			case ']':
+				w.state = ansiOSC
+			default:
+				w.state = ansiText // two-byte sequence such as ESC c
+			}
+		case ansiCSI:
+			if c >= 0x40 && c <= 0x7e {
+				w.state = ansiText
+			}
+		case ansiOSC:
+			if c == 0x07 {
+				w.state = ansiText
+			} else if c == 0x1b {
+				w.state = ansiOSCEscape
+			}
+		case ansiOSCEscape:
+			if c == '\\' {
+				w.state = ansiText
+			} else if c != 0x1b {
+				w.state = ansiOSC
+			}
+		}
+	}
+	return b
+}
+
+// Close closes the underlying writer when it is an io.Closer.
+func (w *StripANSIWriter) Close() error {
+	if c, ok := w.writer.(io.Closer); ok {
+		return c.Close()
+	}
+	return nil
+}
@@ -0,0 +1,54 @@
+// Copyright 2010-2025 the original author or authors.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+
+// 	http://www.apache.org/licenses/LICENSE-2.0
+
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logger_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stella-go/logger"
+)
+
+func TestStripANSIWriter(t *testing.T) {
+	cases := []struct {
+		in       []string
+		expected string
+	}{
+		{[]string{"\x1b[31mERROR\x1b[0m db - failed\n"}, "ERROR db - failed\n"},
+		{[]string{"\x1b[1;38;5;208mbold\x1b[m plain"}, "bold plain"},
+		{[]string{"\x1b]0;title\x07text", "\x1b]8;;http://x\x1b\\link\x1b]8;;\x1b\\"}, "textlink"},
+		{[]string{"split \x1b", "[3", "2mgreen\x1b[", "0m\n"}, "split green\n"},
+		{[]string{"\x1bcreset", "no escape"}, "resetno escape"},
+	}
+	for _, c := range cases {
+		buf := &bytes.Buffer{}
+		w := logger.NewStripANSIWriter(buf)
+		for _, in := range c.in {
+			if n, err := w.Write([]byte(in)); err != nil || n != len(in) {
+				t.Fatalf("unexpected write %d %v", n, err)
+			}
+		}
+		if buf.String() != c.expected {
+			t.Fatalf("%q: unexpected output %q", c.in, buf.String())
+		}
+	}
+
+	buf := &bytes.Buffer{}
+	rootLogger := logger.NewRootLogger(logger.InfoLevel, &logger.PatternFormatter{Pattern: "\x1b[32m%p\x1b[0m %m"}, logger.NewStripANSIWriter(buf))
+	rootLogger.INFO("colored")
+	if buf.String() != "INFO  colored\n" {
+		t.Fatalf("unexpected output %q", buf.String())
+	}
+}
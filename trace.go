@@ -0,0 +1,64 @@
+// Copyright 2010-2025 the original author or authors.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+
+// 	http://www.apache.org/licenses/LICENSE-2.0
+
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logger
+
+import (
+	"context"
+	"sync/atomic"
+)
+
+// TraceIDKey and SpanIDKey are the fields set by WithTraceContext. Only the trace id has
+// a PatternFormatter placeholder, %x: it is the id that correlates lines across services,
+// the span id is kept in the fields for formatters that print them, such as JSON.
+const (
+	TraceIDKey = "trace_id"
+	SpanIDKey  = "span_id"
+)
+
+// TraceExtractor returns the ids of the span active in ctx, ok is false when there is none.
+type TraceExtractor func(ctx context.Context) (traceID string, spanID string, ok bool)
+
+var traceExtractor atomic.Value
+
+// SetTraceExtractor installs the function used by WithTraceContext, the otellog
+// subpackage provides one for OpenTelemetry.
+func SetTraceExtractor(fn TraceExtractor) {
+	traceExtractor.Store(fn)
+}
+
+// WithTraceContext returns a logger adding the trace_id and span_id fields of the span
+// active in ctx, or l itself when no extractor is set or no span is active.
+func (l *Logger) WithTraceContext(ctx context.Context) *Logger {
+	extractor, _ := traceExtractor.Load().(TraceExtractor)
+	if extractor == nil || ctx == nil {
+		return l
+	}
+	traceID, spanID, ok := extractor(ctx)
+	if !ok {
+		return l
+	}
+	c := l.clone()
+	c.fields = mergeFields(l.fields, map[string]interface{}{
+		TraceIDKey: traceID,
+		SpanIDKey:  spanID,
+	})
+	return c
+}
+
+// WithTraceContext is Logger.WithTraceContext on the default logger.
+func WithTraceContext(ctx context.Context) *Logger {
+	xInit()
+	return defaultRootLogger.clone().WithTraceContext(ctx)
+}
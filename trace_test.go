@@ -0,0 +1,67 @@
+// Copyright 2010-2025 the original author or authors.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+
+// 	http://www.apache.org/licenses/LICENSE-2.0
+
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logger_test
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"runtime"
+	"testing"
+
+	"github.com/stella-go/logger"
+)
+
+type traceKey struct{}
+
+func TestWithTraceContext(t *testing.T) {
+	logger.SetTraceExtractor(func(ctx context.Context) (string, string, bool) {
+		ids, ok := ctx.Value(traceKey{}).([2]string)
+		return ids[0], ids[1], ok
+	})
+	defer logger.SetTraceExtractor(nil)
+
+	buf := &bytes.Buffer{}
+	rootLogger := logger.NewRootLogger(logger.InfoLevel, &logger.PatternFormatter{Pattern: "[%x] %m"}, buf)
+	ctx := context.WithValue(context.Background(), traceKey{}, [2]string{"4bf92f3577b34da6a3ce929d0e0e4736", "00f067aa0ba902b7"})
+	rootLogger.WithTraceContext(ctx).INFO("traced")
+	rootLogger.WithTraceContext(context.Background()).INFO("untraced")
+
+	if buf.String() != "[4bf92f3577b34da6a3ce929d0e0e4736] traced\n[] untraced\n" {
+		t.Fatalf("unexpected output:\n%s", buf.String())
+	}
+}
+
+func TestPackageWithTraceContext(t *testing.T) {
+	logger.SetTraceExtractor(func(ctx context.Context) (string, string, bool) {
+		ids, ok := ctx.Value(traceKey{}).([2]string)
+		return ids[0], ids[1], ok
+	})
+	defer logger.SetTraceExtractor(nil)
+
+	buf := &bytes.Buffer{}
+	rootLogger := logger.NewRootLogger(logger.InfoLevel, &logger.PatternFormatter{Pattern: "%l [%x] %m"}, buf)
+	defer logger.SetDefaultRootLogger(rootLogger)()
+	ctx := context.WithValue(context.Background(), traceKey{}, [2]string{"4bf92f3577b34da6a3ce929d0e0e4736", "00f067aa0ba902b7"})
+
+	_, _, line, _ := runtime.Caller(0)
+	logger.WithTraceContext(ctx).INFO("traced")
+	logger.WithTraceContext(context.Background()).INFO("untraced")
+
+	expected := fmt.Sprintf("trace_test.go:%d [4bf92f3577b34da6a3ce929d0e0e4736] traced\ntrace_test.go:%d [] untraced\n", line+1, line+2)
+	if buf.String() != expected {
+		t.Fatalf("unexpected output:\n%s\nexpected:\n%s", buf.String(), expected)
+	}
+}